@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The transcripts in testdata/protocol describe the text protocol: a line
+// starting with "> " is a command and the lines after it are the expected
+// reply. They run against HandleCommand, and also against an external
+// binary over stdin/stdout when BATTLESHIP_BINARY names one.
+const conformanceTimeout = 5 * time.Second
+
+type transcriptStep struct {
+	line     int
+	command  string
+	expected []string
+}
+
+func readTranscript(path string) ([]transcriptStep, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	steps := make([]transcriptStep, 0)
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "> "):
+			steps = append(steps, transcriptStep{line: lineNumber, command: strings.TrimPrefix(line, "> ")})
+		case len(steps) == 0:
+			return nil, fmt.Errorf("%s:%d: reply before the first command", path, lineNumber)
+		default:
+			step := &steps[len(steps)-1]
+			step.expected = append(step.expected, line)
+		}
+	}
+	return steps, scanner.Err()
+}
+
+type protocolPeer interface {
+	Exchange(command string, replyLines int) ([]string, error)
+	Close()
+}
+
+type gamePeer struct {
+	game *Game
+}
+
+func (p *gamePeer) Exchange(command string, replyLines int) ([]string, error) {
+	reply := p.game.HandleCommand(command)
+	if reply == "" {
+		return nil, nil
+	}
+	return strings.Split(reply, "\n"), nil
+}
+
+func (p *gamePeer) Close() {}
+
+type processPeer struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	lines chan string
+}
+
+func startProcessPeer(binary string) (*processPeer, error) {
+	cmd := exec.Command(binary)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	return &processPeer{cmd: cmd, stdin: stdin, lines: lines}, nil
+}
+
+// Exchange sends one command and reads as many lines as the transcript
+// expects. Prompts printed before a reply are stripped.
+func (p *processPeer) Exchange(command string, replyLines int) ([]string, error) {
+	if _, err := fmt.Fprintf(p.stdin, "%s\n", command); err != nil {
+		return nil, err
+	}
+	reply := make([]string, 0, replyLines)
+	for len(reply) < replyLines {
+		select {
+		case line, ok := <-p.lines:
+			if !ok {
+				return reply, fmt.Errorf("binary exited")
+			}
+			for strings.HasPrefix(line, "> ") {
+				line = strings.TrimPrefix(line, "> ")
+			}
+			reply = append(reply, strings.TrimRight(line, "\r"))
+		case <-time.After(conformanceTimeout):
+			return reply, fmt.Errorf("no reply after %v", conformanceTimeout)
+		}
+	}
+	return reply, nil
+}
+
+func (p *processPeer) Close() {
+	p.stdin.Close()
+	p.cmd.Process.Kill()
+	p.cmd.Wait()
+}
+
+func runTranscript(t *testing.T, path string, peer protocolPeer) {
+	steps, err := readTranscript(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peer.Close()
+	for _, step := range steps {
+		reply, err := peer.Exchange(step.command, len(step.expected))
+		if err != nil {
+			t.Fatalf("%s:%d: %q: %v", filepath.Base(path), step.line, step.command, err)
+		}
+		if strings.Join(reply, "\n") != strings.Join(step.expected, "\n") {
+			t.Errorf("%s:%d: %q: expected %q, got %q", filepath.Base(path), step.line, step.command, step.expected, reply)
+		}
+	}
+}
+
+func TestConformance(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "protocol", "*.txt"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no transcripts found: %v", err)
+	}
+	binary := os.Getenv("BATTLESHIP_BINARY")
+
+	for _, path := range paths {
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".txt"), func(t *testing.T) {
+			if binary == "" {
+				runTranscript(t, path, &gamePeer{game: NewGame()})
+				return
+			}
+			peer, err := startProcessPeer(binary)
+			if err != nil {
+				t.Fatalf("starting %s: %v", binary, err)
+			}
+			runTranscript(t, path, peer)
+		})
+	}
+}
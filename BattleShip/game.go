@@ -26,165 +26,206 @@ const (
 	KILL
 )
 
+func (r Role) String() string {
+	switch r {
+	case MASTER:
+		return "master"
+	case SLAVE:
+		return "slave"
+	}
+	return "none"
+}
+
+func (r ShotResult) String() string {
+	switch r {
+	case HIT:
+		return "hit"
+	case KILL:
+		return "kill"
+	}
+	return "miss"
+}
+
 type Ship struct {
 	Size       int
 	IsVertical bool
 	X, Y       int
 	Hits       int
+	Shape      *Shape
+	Rotation   int
 }
 
 type Pair struct {
-	X, Y int
+	X int `json:"x"`
+	Y int `json:"y"`
 }
 
 type Game struct {
 	role           Role
 	width, height  int
 	shipCounts     map[int]int
-	field          [][]rune
+	shapeCounts    map[string]int
+	shipCells      map[Pair]int
+	shotCells      map[Pair]bool
 	ships          []Ship
 	shotHistory    []Pair
-	strategy       string
+	strategyName   string
+	strategy       Strategy
+	placement      string
 	gameStarted    bool
 	allShipsPlaced bool
 	isGameCreated  bool
-	nextShotX      int
-	nextShotY      int
 	lastShotResult ShotResult
-	hits           []Pair
+	lastShot       Pair
+	pendingShots   []Pair
+	target         *TargetBoard
+	rules          Rules
+	seed           int64
+	source         *countingSource
+	rng            *rand.Rand
+	journal        *Journal
 }
 
 func NewGame() *Game {
-	rand.Seed(time.Now().UnixNano())
-	return &Game{
+	g := &Game{
 		role:           NONE,
 		width:          0,
 		height:         0,
 		shipCounts:     make(map[int]int),
-		field:          nil,
+		shapeCounts:    make(map[string]int),
+		shipCells:      make(map[Pair]int),
+		shotCells:      make(map[Pair]bool),
 		ships:          make([]Ship, 0),
 		shotHistory:    make([]Pair, 0),
-		strategy:       "custom",
+		placement:      "random",
 		gameStarted:    false,
 		allShipsPlaced: false,
 		isGameCreated:  false,
-		nextShotX:      0,
-		nextShotY:      0,
 		lastShotResult: MISS,
-		hits:           make([]Pair, 0),
+		target:         NewTargetBoard(),
+		rules:          DefaultRules(),
 	}
+	g.SetStrategy("custom")
+	g.SetSeed(time.Now().UnixNano())
+	return g
 }
 
 func (g *Game) IsValidCoordinate(x, y int) bool {
 	return x >= 0 && x < g.width && y >= 0 && y < g.height
 }
 
+func (g *Game) IsCellFree(x, y int) bool {
+	cell := Pair{X: x, Y: y}
+	if _, ok := g.shipCells[cell]; ok {
+		return false
+	}
+	return !g.shotCells[cell]
+}
+
+func (g *Game) ResetBoard() {
+	g.ships = make([]Ship, 0)
+	g.shipCells = make(map[Pair]int)
+	g.shotCells = make(map[Pair]bool)
+	g.shotHistory = make([]Pair, 0)
+}
+
+// clearPlacement drops a fleet placed for an older setup, so that start
+// places it again for the current board and rules.
+func (g *Game) clearPlacement() {
+	g.ships = make([]Ship, 0)
+	g.shipCells = make(map[Pair]int)
+	g.allShipsPlaced = false
+}
+
 func (g *Game) CanPlaceShip(size, x, y int, isVertical bool) bool {
-	if isVertical {
-		if y+size > g.height {
+	if !g.IsValidCoordinate(x, y) || !g.rules.IsSizeAllowed(size) {
+		return false
+	}
+	if isVertical && y+size > g.height {
+		return false
+	}
+	if !isVertical && x+size > g.width {
+		return false
+	}
+	return g.CanPlace(Ship{Size: size, IsVertical: isVertical, X: x, Y: y})
+}
+
+// CanPlace checks a ship of any shape against the board and the rules.
+func (g *Game) CanPlace(ship Ship) bool {
+	for _, cell := range ship.Cells() {
+		if !g.IsValidCoordinate(cell.X, cell.Y) || !g.IsCellFree(cell.X, cell.Y) {
 			return false
 		}
-		for i := 0; i < size; i++ {
-			if g.field[y+i][x] != '.' {
-				return false
-			}
-		}
-	} else {
-		if x+size > g.width {
+		if g.rules.NoTouch && g.touchesShip(cell.X, cell.Y) {
 			return false
 		}
-		for i := 0; i < size; i++ {
-			if g.field[y][x+i] != '.' {
-				return false
-			}
-		}
 	}
 	return true
 }
 
 func (g *Game) PlaceShip(size, x, y int, isVertical bool) {
-	newShip := Ship{
+	g.Place(Ship{
 		Size:       size,
 		IsVertical: isVertical,
 		X:          x,
 		Y:          y,
 		Hits:       0,
+	})
+}
+
+func (g *Game) Place(ship Ship) {
+	if g.journal != nil {
+		g.journal.Record("place %s", ship.Placement())
 	}
-	g.ships = append(g.ships, newShip)
-	if isVertical {
-		for i := 0; i < size; i++ {
-			g.field[y+i][x] = 'S'
-		}
-	} else {
-		for i := 0; i < size; i++ {
-			g.field[y][x+i] = 'S'
-		}
+	g.addShip(ship)
+}
+
+func (g *Game) addShip(ship Ship) {
+	index := len(g.ships)
+	g.ships = append(g.ships, ship)
+	for _, cell := range ship.Cells() {
+		g.shipCells[cell] = index
 	}
 }
 
-func (g *Game) RandomizeShipPlacement() error {
-	g.ships = make([]Ship, 0)
-	g.field = make([][]rune, g.height)
-	for i := range g.field {
-		g.field[i] = make([]rune, g.width)
-		for j := range g.field[i] {
-			g.field[i][j] = '.'
-		}
-	}
-
-	totalShipCells := 0
-	for size, count := range g.shipCounts {
-		totalShipCells += size * count
-	}
-	if totalShipCells > g.width*g.height {
-		return fmt.Errorf("Error: not enough space for all ships")
-	}
-
-	for size := 1; size <= 4; size++ {
-		count := g.shipCounts[size]
-		for i := 0; i < count; i++ {
-			placed := false
-			attempts := 0
-			for !placed && attempts < 100 {
-				x := rand.Intn(g.width)
-				y := rand.Intn(g.height)
-				isVertical := rand.Intn(2) == 0
-				if g.IsValidCoordinate(x, y) && g.CanPlaceShip(size, x, y, isVertical) {
-					g.PlaceShip(size, x, y, isVertical)
-					placed = true
-				}
-				attempts++
-			}
-			if !placed {
-				return fmt.Errorf("Failed to place ships")
-			}
-		}
+func (g *Game) removeLastShip() {
+	index := len(g.ships) - 1
+	for _, cell := range g.ships[index].Cells() {
+		delete(g.shipCells, cell)
 	}
-	return nil
+	g.ships = g.ships[:index]
 }
 
-func (g *Game) HandleStartCommand() string {
-	if g.width <= 0 || g.height <= 0 {
-		return "failed"
+func (s Ship) Cells() []Pair {
+	cells := make([]Pair, s.Size)
+	for i := range cells {
+		cells[i] = s.Cell(i)
 	}
+	return cells
+}
 
-	hasShips := false
-	for i := 1; i <= 4; i++ {
-		if g.shipCounts[i] > 0 {
-			hasShips = true
-			break
-		}
+// Cell returns the i-th cell the ship covers.
+func (s Ship) Cell(i int) Pair {
+	if s.Shape != nil {
+		offset := s.Shape.Rotations[s.Rotation][i]
+		return Pair{X: s.X + offset.X, Y: s.Y + offset.Y}
 	}
-	if !hasShips {
+	if s.IsVertical {
+		return Pair{X: s.X, Y: s.Y + i}
+	}
+	return Pair{X: s.X + i, Y: s.Y}
+}
+
+func (g *Game) HandleStartCommand() string {
+	if g.width <= 0 || g.height <= 0 {
 		return "failed"
 	}
 
-	totalShipsPlaced := len(g.ships)
-	totalShipsCount := 0
-	for i := 1; i <= 4; i++ {
-		totalShipsCount += g.shipCounts[i]
+	totalShipsCount := g.TotalShipCount()
+	if totalShipsCount == 0 {
+		return "failed"
 	}
-	if totalShipsPlaced < totalShipsCount {
+	if len(g.ships) < totalShipsCount {
 		if !g.allShipsPlaced {
 			err := g.RandomizeShipPlacement()
 			if err != nil {
@@ -207,24 +248,7 @@ func (g *Game) SaveToFile(path string) error {
 	}
 	defer file.Close()
 
-	writer := bufio.NewWriter(file)
-	_, err = fmt.Fprintf(writer, "%d %d\n", g.width, g.height)
-	if err != nil {
-		return err
-	}
-
-	for _, ship := range g.ships {
-		orientation := "h"
-		if ship.IsVertical {
-			orientation = "v"
-		}
-		_, err = fmt.Fprintf(writer, "%d %s %d %d\n", ship.Size, orientation, ship.X, ship.Y)
-		if err != nil {
-			return err
-		}
-	}
-
-	return writer.Flush()
+	return g.WriteState(file)
 }
 
 func (g *Game) LoadFromFile(path string) error {
@@ -240,6 +264,9 @@ func (g *Game) LoadFromFile(path string) error {
 	}
 
 	fields := strings.Fields(scanner.Text())
+	if len(fields) > 0 && fields[0] == saveFormatHeader {
+		return g.ReadState(fields, scanner)
+	}
 	if len(fields) != 2 {
 		return fmt.Errorf("Invalid field dimensions format")
 	}
@@ -260,16 +287,9 @@ func (g *Game) LoadFromFile(path string) error {
 
 	g.width = width
 	g.height = height
-	g.ships = make([]Ship, 0)
 	g.shipCounts = make(map[int]int)
-
-	g.field = make([][]rune, g.height)
-	for i := range g.field {
-		g.field[i] = make([]rune, g.width)
-		for j := range g.field[i] {
-			g.field[i][j] = '.'
-		}
-	}
+	g.shapeCounts = make(map[string]int)
+	g.ResetBoard()
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -318,137 +338,54 @@ func (g *Game) HandleShotCommand(x, y int) string {
 	if !g.IsValidCoordinate(x, y) {
 		return "failed"
 	}
-	for _, shot := range g.shotHistory {
-		if shot.X == x && shot.Y == y {
-			return "failed"
-		}
-	}
-	g.shotHistory = append(g.shotHistory, Pair{X: x, Y: y})
-	if g.field[y][x] == 'S' {
-		for i := range g.ships {
-			ship := &g.ships[i]
-			if ship.IsVertical {
-				if x == ship.X && y >= ship.Y && y < ship.Y+ship.Size {
-					ship.Hits++
-					g.field[y][x] = 'X'
-					return func() string {
-						if ship.Hits == ship.Size {
-							return "kill"
-						}
-						return "hit"
-					}()
-				}
-			} else {
-				if y == ship.Y && x >= ship.X && x < ship.X+ship.Size {
-					ship.Hits++
-					g.field[y][x] = 'X'
-					return func() string {
-						if ship.Hits == ship.Size {
-							return "kill"
-						}
-						return "hit"
-					}()
-				}
+	cell := Pair{X: x, Y: y}
+	if g.shotCells[cell] {
+		return "failed"
+	}
+	g.shotCells[cell] = true
+	g.shotHistory = append(g.shotHistory, cell)
+	if index, ok := g.shipCells[cell]; ok {
+		ship := &g.ships[index]
+		ship.Hits++
+		if ship.Hits == ship.Size {
+			if g.rules.RevealSize {
+				return fmt.Sprintf("kill %d", ship.Size)
 			}
+			return "kill"
 		}
+		return "hit"
 	}
-	g.field[y][x] = 'O'
 	return "miss"
 }
 
 func (g *Game) ProcessShotResult(result string) {
-	switch result {
-	case "hit":
-		g.lastShotResult = HIT
-	case "kill":
-		g.lastShotResult = KILL
-		g.hits = nil
-	case "miss":
-		g.lastShotResult = MISS
+	reports, ok := ParseShotReports(strings.Fields(result))
+	if ok {
+		g.ProcessShotReports(reports)
 	}
 }
 
-func (g *Game) GetNextShotFromHit(x, y int) Pair {
-	nextShot := Pair{X: x + 1, Y: y}
-	if g.IsValidCoordinate(nextShot.X, nextShot.Y) && g.field[nextShot.Y][nextShot.X] == '.' {
-		return nextShot
-	}
-	nextShot = Pair{X: x - 1, Y: y}
-	if g.IsValidCoordinate(nextShot.X, nextShot.Y) && g.field[nextShot.Y][nextShot.X] == '.' {
-		return nextShot
-	}
-	nextShot = Pair{X: x, Y: y + 1}
-	if g.IsValidCoordinate(nextShot.X, nextShot.Y) && g.field[nextShot.Y][nextShot.X] == '.' {
-		return nextShot
-	}
-	nextShot = Pair{X: x, Y: y - 1}
-	if g.IsValidCoordinate(nextShot.X, nextShot.Y) && g.field[nextShot.Y][nextShot.X] == '.' {
-		return nextShot
+func (g *Game) GetNextShot() Pair {
+	volley := g.GetNextVolley(1)
+	if len(volley) == 0 {
+		return Pair{X: -1, Y: -1}
 	}
-	return Pair{X: -1, Y: -1}
+	return volley[0]
 }
 
-func (g *Game) ProcessHit(x, y int) {
-	g.hits = append(g.hits, Pair{X: x, Y: y})
-	g.lastShotResult = HIT
+func (g *Game) IsGameFinished() bool {
+	return g.IsWinner() || g.IsLoser()
 }
 
-func (g *Game) GetNextShot() Pair {
-	if g.strategy == "custom" {
-		if len(g.hits) > 0 {
-			if len(g.hits) == 2 {
-				hit1 := g.hits[0]
-				hit2 := g.hits[1]
-				x1, y1 := hit1.X, hit1.Y
-				x2, y2 := hit2.X, hit2.Y
-				if x1 == x2 {
-					if y1 < y2 {
-						return g.GetNextShotFromHit(x1, y2+1)
-					} else {
-						return g.GetNextShotFromHit(x1, y1-1)
-					}
-				} else if y1 == y2 {
-					if x1 < x2 {
-						return g.GetNextShotFromHit(x2+1, y1)
-					} else {
-						return g.GetNextShotFromHit(x1-1, y1)
-					}
-				}
-			}
-			for _, hit := range g.hits {
-				nextShot := g.GetNextShotFromHit(hit.X, hit.Y)
-				if nextShot.X != -1 && nextShot.Y != -1 {
-					return nextShot
-				}
-			}
-		}
-		for {
-			x := rand.Intn(g.width)
-			y := rand.Intn(g.height)
-			if g.field[y][x] == '.' {
-				return Pair{X: x, Y: y}
-			}
-		}
-	}
-	if g.strategy == "ordered" {
-		if g.nextShotX == 0 && g.nextShotY == 0 {
-			g.nextShotX = 0
-			g.nextShotY = 0
-		}
-		if g.nextShotY < g.height {
-			nextShot := Pair{X: g.nextShotX, Y: g.nextShotY}
-			g.nextShotX++
-			if g.nextShotX >= g.width {
-				g.nextShotX = 0
-				g.nextShotY++
-			}
-			return nextShot
-		}
-	}
-	return Pair{X: -1, Y: -1}
+func (g *Game) IsWinner() bool {
+	totalShips := g.TotalShipCount()
+	return totalShips > 0 && g.target.TotalSunk() >= totalShips
 }
 
-func (g *Game) IsGameFinished() bool {
+func (g *Game) IsLoser() bool {
+	if len(g.ships) == 0 {
+		return false
+	}
 	for _, ship := range g.ships {
 		if ship.Hits < ship.Size {
 			return false
@@ -457,18 +394,51 @@ func (g *Game) IsGameFinished() bool {
 	return true
 }
 
-func (g *Game) IsWinner() bool {
-	return g.IsGameFinished()
+func (g *Game) Outcome() string {
+	if g.IsWinner() {
+		return "win"
+	}
+	if g.IsLoser() {
+		return "lose"
+	}
+	return "none"
 }
 
-func (g *Game) IsLoser() bool {
-	return g.IsGameFinished()
+// Execute runs one protocol command. It never prints, blocks on the network
+// or exits the process; "exit" comes back as a response with Shutdown set.
+// The commands that take over the terminal or serve a match are run by the
+// REPL in main.go.
+func (g *Game) Execute(commandLine string) Response {
+	wasFinished := g.IsGameFinished()
+	response := g.executeCommand(commandLine)
+	if g.journal != nil && !isUnrecordedCommand(commandLine) {
+		if !wasFinished && g.IsGameFinished() {
+			g.journal.Record("outcome %s", g.Outcome())
+		}
+		g.journal.Record("cmd %s -> %s", strings.Join(strings.Fields(commandLine), " "), response)
+	}
+	return response
 }
 
+// HandleCommand runs a command and returns its reply as protocol text.
 func (g *Game) HandleCommand(commandLine string) string {
+	return g.Execute(commandLine).String()
+}
+
+// isSetupParameter reports whether a "set" parameter describes the board
+// and the fleet, which cannot change once the game has started.
+func isSetupParameter(param string) bool {
+	switch param {
+	case "width", "height", "count", "shape", "placement", "rule":
+		return true
+	}
+	return false
+}
+
+func (g *Game) executeCommand(commandLine string) Response {
 	fields := strings.Fields(commandLine)
 	if len(fields) == 0 {
-		return "failed"
+		return Failed()
 	}
 
 	command := fields[0]
@@ -476,15 +446,16 @@ func (g *Game) HandleCommand(commandLine string) string {
 
 	switch command {
 	case "ping":
-		return "pong"
+		return Reply("pong")
 	case "exit":
-		os.Exit(0)
+		return Response{Status: StatusOK, Message: "ok", Shutdown: true}
 	case "create":
 		if len(args) < 1 {
-			return "failed"
+			return Failed()
 		}
 		roleStr := args[0]
 		g.isGameCreated = true
+		g.strategy.Reset()
 		switch roleStr {
 		case "master":
 			g.role = MASTER
@@ -494,170 +465,260 @@ func (g *Game) HandleCommand(commandLine string) string {
 			g.shipCounts[2] = 2
 			g.shipCounts[3] = 2
 			g.shipCounts[4] = 1
-			err := g.RandomizeShipPlacement()
-			if err != nil {
-				return "failed"
-			}
 		case "slave":
 			g.role = SLAVE
 		default:
 			g.role = NONE
 		}
 		if g.role != NONE {
-			return "ok"
+			return Reply("ok")
 		}
-		return "failed"
+		return Failed()
 	case "start":
-		return g.HandleStartCommand()
+		return Reply(g.HandleStartCommand())
 	case "stop":
 		if g.gameStarted {
 			g.gameStarted = false
-			return "ok"
+			return Reply("ok")
 		}
-		return "failed"
+		return Failed()
 	case "set":
 		if len(args) < 2 {
-			return "failed"
+			return Failed()
 		}
 		param := args[0]
-		switch param {
-		case "width":
-			value, err := strconv.Atoi(args[1])
-			if err != nil || value <= 0 {
-				return "failed"
-			}
-			g.width = value
-			return "ok"
-		case "height":
-			value, err := strconv.Atoi(args[1])
-			if err != nil || value <= 0 {
-				return "failed"
-			}
-			g.height = value
-			return "ok"
-		case "count":
-			if len(args) < 3 {
-				return "failed"
-			}
-			typeValue, err := strconv.Atoi(args[1])
-			if err != nil || typeValue < 1 || typeValue > 4 {
-				return "failed"
-			}
-			value, err := strconv.Atoi(args[2])
-			if err != nil || value < 0 {
-				return "failed"
-			}
-			g.shipCounts[typeValue] = value
-			return "ok"
-		case "strategy":
-			if len(args) < 2 {
-				return "failed"
-			}
-			strat := args[1]
-			if strat == "ordered" || strat == "custom" {
-				g.strategy = strat
-				return "ok"
-			}
-			return "failed"
-		case "result":
-			if len(args) < 2 {
-				return "failed"
-			}
-			result := args[1]
-			if result == "miss" {
-				g.lastShotResult = MISS
-				return "ok"
-			} else if result == "hit" {
-				g.lastShotResult = HIT
-				return "ok"
-			} else if result == "kill" {
-				g.lastShotResult = KILL
-				return "ok"
-			}
-			return "failed"
+		if !isSetupParameter(param) {
+			return g.executeSetCommand(param, args)
 		}
-		return "failed"
+		if g.gameStarted {
+			return Failed()
+		}
+		response := g.executeSetCommand(param, args)
+		if response.Status == StatusOK {
+			g.clearPlacement()
+		}
+		return response
 	case "get":
 		if len(args) < 1 {
-			return "failed"
+			return Failed()
 		}
 		param := args[0]
 		switch param {
 		case "width":
 			if g.width > 0 {
-				return strconv.Itoa(g.width)
+				return Reply(strconv.Itoa(g.width))
 			}
-			return "failed"
+			return Failed()
 		case "height":
 			if g.height > 0 {
-				return strconv.Itoa(g.height)
+				return Reply(strconv.Itoa(g.height))
 			}
-			return "failed"
+			return Failed()
 		case "count":
 			if len(args) < 2 {
-				return "failed"
+				return Failed()
 			}
 			typeValue, err := strconv.Atoi(args[1])
-			if err != nil || typeValue < 1 || typeValue > 4 {
-				return "failed"
+			if err != nil || !g.rules.IsSizeAllowed(typeValue) {
+				return Failed()
+			}
+			return Reply(strconv.Itoa(g.shipCounts[typeValue]))
+		case "shape":
+			if len(args) < 2 {
+				return Failed()
 			}
-			return strconv.Itoa(g.shipCounts[typeValue])
+			if _, ok := g.rules.Shape(args[1]); !ok {
+				return Failed()
+			}
+			return Reply(strconv.Itoa(g.shapeCounts[args[1]]))
 		case "strategy":
-			return g.strategy
-		}
-		return "failed"
+			return Reply(g.strategyName)
+		case "placement":
+			return Reply(g.placement)
+		case "rule":
+			return Reply(g.HandleGetRuleCommand(args[1:]))
+		case "seed":
+			return Reply(strconv.FormatInt(g.seed, 10))
+		}
+		return Failed()
 	case "shot":
 		if len(args) == 0 {
-			nextShot := g.GetNextShot()
-			if nextShot.X >= 0 && nextShot.Y >= 0 {
-				fmt.Printf("%d %d\n", nextShot.X, nextShot.Y)
-				return ""
+			if !g.gameStarted {
+				return Failed()
 			}
-			return "failed"
-		} else if len(args) >= 2 {
-			x, err1 := strconv.Atoi(args[0])
-			y, err2 := strconv.Atoi(args[1])
-			if err1 != nil || err2 != nil {
-				return "failed"
+			volley := g.GetNextVolley(g.VolleySize())
+			if len(volley) > 0 {
+				return ShotResponse(volley...)
 			}
-			return g.HandleShotCommand(x, y)
+			return Failed()
 		}
-		return "failed"
+		values, err := atoiAll(args)
+		if err != nil || len(values)%2 != 0 {
+			return Failed()
+		}
+		cells := make([]Pair, len(values)/2)
+		for i := range cells {
+			cells[i] = Pair{X: values[2*i], Y: values[2*i+1]}
+		}
+		if len(cells) == 1 {
+			return Reply(g.HandleShotCommand(cells[0].X, cells[0].Y))
+		}
+		return Reply(g.HandleVolleyCommand(cells))
 	case "finished":
 		if g.IsGameFinished() {
-			return "yes"
+			return Reply("yes")
 		}
-		return "no"
+		return Reply("no")
 	case "win":
 		if g.IsWinner() {
-			return "yes"
+			return Reply("yes")
 		}
-		return "no"
+		return Reply("no")
 	case "lose":
 		if g.IsLoser() {
-			return "yes"
+			return Reply("yes")
 		}
-		return "no"
+		return Reply("no")
 	case "dump":
 		if len(args) < 1 {
-			return "failed"
+			return Failed()
 		}
 		path := args[0]
 		err := g.SaveToFile(path)
 		if err != nil {
-			return "failed"
+			return Failed()
 		}
-		return "ok"
+		return Reply("ok")
 	case "load":
 		if len(args) < 1 {
-			return "failed"
+			return Failed()
 		}
 		path := args[0]
 		err := g.LoadFromFile(path)
 		if err != nil {
-			return "failed"
+			return Failed()
+		}
+		return Reply("ok")
+	case "show":
+		return Reply(g.HandleShowCommand(args))
+	case "stats":
+		return Reply(g.HandleStatsCommand(args))
+	case "tournament":
+		return Reply(g.HandleTournamentCommand(args))
+	case "journal":
+		if len(args) < 1 {
+			return Failed()
+		}
+		if args[0] == "off" {
+			if g.journal == nil {
+				return Failed()
+			}
+			err := g.journal.Close()
+			g.journal = nil
+			if err != nil {
+				return Failed()
+			}
+			return Reply("ok")
+		}
+		if g.journal != nil || g.isGameCreated {
+			return Failed()
+		}
+		journal, err := CreateJournal(args[0])
+		if err != nil {
+			return Failed()
+		}
+		g.SetSeed(g.seed)
+		g.journal = journal
+		g.journal.Record("seed %d", g.seed)
+		return Reply("ok")
+	case "replay":
+		if len(args) < 1 {
+			return Failed()
+		}
+		_, err := ReplayJournal(args[0])
+		if err != nil {
+			return Failed()
+		}
+		return Reply("ok")
+	}
+	return Failed()
+}
+
+// executeSetCommand changes one setting; args starts with its name.
+func (g *Game) executeSetCommand(param string, args []string) Response {
+	switch param {
+	case "width":
+		value, err := strconv.Atoi(args[1])
+		if err != nil || value <= 0 {
+			return Failed()
+		}
+		g.width = value
+		return Reply("ok")
+	case "height":
+		value, err := strconv.Atoi(args[1])
+		if err != nil || value <= 0 {
+			return Failed()
+		}
+		g.height = value
+		return Reply("ok")
+	case "count":
+		if len(args) < 3 {
+			return Failed()
+		}
+		typeValue, err := strconv.Atoi(args[1])
+		if err != nil || !g.rules.IsSizeAllowed(typeValue) {
+			return Failed()
+		}
+		value, err := strconv.Atoi(args[2])
+		if err != nil || value < 0 {
+			return Failed()
+		}
+		g.shipCounts[typeValue] = value
+		return Reply("ok")
+	case "shape":
+		if len(args) < 3 {
+			return Failed()
+		}
+		if _, ok := g.rules.Shape(args[1]); !ok {
+			return Failed()
+		}
+		value, err := strconv.Atoi(args[2])
+		if err != nil || value < 0 {
+			return Failed()
+		}
+		g.shapeCounts[args[1]] = value
+		return Reply("ok")
+	case "strategy":
+		if len(args) < 2 {
+			return Failed()
+		}
+		if g.SetStrategy(args[1]) {
+			return Reply("ok")
+		}
+		return Failed()
+	case "placement":
+		if g.SetPlacement(args[1]) {
+			return Reply("ok")
+		}
+		return Failed()
+	case "rule":
+		return Reply(g.HandleSetRuleCommand(args[1:]))
+	case "seed":
+		value, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return Failed()
+		}
+		g.SetSeed(value)
+		return Reply("ok")
+	case "result":
+		if len(args) < 2 {
+			return Failed()
+		}
+		reports, ok := ParseShotReports(args[1:])
+		if ok && g.ProcessShotReports(reports) {
+			return Reply("ok")
 		}
-		return "ok"
+		return Failed()
 	}
-	return "failed"
+	return Failed()
 }
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestLargeBoardIsSparse(t *testing.T) {
+	g := NewGame()
+	runCommands(t, g, "create master", "start")
+
+	cells := 0
+	for size, count := range g.shipCounts {
+		cells += size * count
+	}
+	if len(g.shipCells) != cells {
+		t.Errorf("Expected %d ship cells, got %d", cells, len(g.shipCells))
+	}
+
+	ship := g.ships[0]
+	if reply := g.HandleCommand("shot 74999 74999"); reply == "failed" {
+		t.Errorf("Expected shot at the far corner to be accepted")
+	}
+	if reply := g.HandleShotCommand(ship.X, ship.Y); reply != "hit" && reply != "kill" {
+		t.Errorf("Expected hit at ship origin, got %s", reply)
+	}
+	if reply := g.HandleShotCommand(ship.X, ship.Y); reply != "failed" {
+		t.Errorf("Expected repeated shot to fail, got %s", reply)
+	}
+	if len(g.shotCells) != 2 {
+		t.Errorf("Expected 2 recorded shots, got %d", len(g.shotCells))
+	}
+
+	next := g.GetNextShot()
+	if !g.IsValidCoordinate(next.X, next.Y) || !g.IsCellFree(next.X, next.Y) {
+		t.Errorf("Expected next shot on a free cell, got %v", next)
+	}
+}
+
+func TestNoTouchRule(t *testing.T) {
+	g := NewGame()
+	g.width = 10
+	g.height = 10
+	runCommands(t, g, "set rule notouch on", "set rule sizes 1,2,5")
+	if reply := g.HandleCommand("get rule sizes"); reply != "1,2,5" {
+		t.Errorf("Expected sizes 1,2,5, got %s", reply)
+	}
+	if reply := g.HandleCommand("set count 4 1"); reply != "failed" {
+		t.Errorf("Expected size 4 to be rejected, got %s", reply)
+	}
+
+	g.PlaceShip(2, 3, 3, false)
+	if g.CanPlaceShip(1, 5, 4, false) {
+		t.Errorf("Expected diagonal contact to be rejected")
+	}
+	if g.CanPlaceShip(1, 3, 2, false) {
+		t.Errorf("Expected side contact to be rejected")
+	}
+	if !g.CanPlaceShip(1, 6, 3, false) {
+		t.Errorf("Expected a separated ship to be accepted")
+	}
+	if g.CanPlaceShip(3, 0, 0, false) {
+		t.Errorf("Expected a size outside the rules to be rejected")
+	}
+
+	g.HandleCommand("set count 5 1")
+	g.HandleCommand("set count 2 3")
+	g.HandleCommand("set count 1 4")
+	if err := g.RandomizeShipPlacement(); err != nil {
+		t.Fatalf("placement: %v", err)
+	}
+	for cell, index := range g.shipCells {
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				other, ok := g.shipCells[Pair{X: cell.X + dx, Y: cell.Y + dy}]
+				if ok && other != index {
+					t.Fatalf("Ships %d and %d touch at %v", index, other, cell)
+				}
+			}
+		}
+	}
+}
+
+func TestTrackingBoardIsSeparate(t *testing.T) {
+	g := NewGame()
+	g.width = 3
+	g.height = 1
+	g.shipCounts[1] = 1
+	g.PlaceShip(1, 0, 0, false)
+	g.HandleCommand("set strategy ordered")
+
+	shot := g.GetNextShot()
+	if shot != (Pair{X: 0, Y: 0}) {
+		t.Fatalf("Expected ordered shot at (0, 0), got %v", shot)
+	}
+	g.HandleCommand("set result kill")
+
+	if _, ok := g.target.Result(shot); !ok {
+		t.Errorf("Expected our shot to be tracked on the opponent board")
+	}
+	if g.shotCells[shot] || g.ships[0].Hits != 0 {
+		t.Errorf("Expected our own fleet to be untouched by our shot")
+	}
+	if g.HandleCommand("win") != "yes" || g.HandleCommand("lose") != "no" {
+		t.Errorf("Expected win after sinking the only opponent ship")
+	}
+}
+
+func TestExecuteReturnsStructuredResponses(t *testing.T) {
+	g := NewGame()
+	for _, command := range []string{"create slave", "set width 2", "set height 1", "set count 1 1", "set strategy ordered", "start"} {
+		if response := g.Execute(command); response.Status != StatusOK {
+			t.Fatalf("%q: got %v", command, response)
+		}
+	}
+
+	response := g.Execute("shot")
+	if len(response.Shots) != 1 || response.Shots[0] != (Pair{X: 0, Y: 0}) || response.String() != "0 0" {
+		t.Errorf("Expected a shot at 0 0, got %+v", response)
+	}
+	if response := g.Execute("set width 3"); response.Status != StatusFailed || response.String() != "failed" {
+		t.Errorf("Expected set after start to fail, got %+v", response)
+	}
+	if response := g.Execute("exit"); !response.Shutdown {
+		t.Errorf("Expected exit to ask for a shutdown, got %+v", response)
+	}
+}
+
+func TestMasterFleetFollowsSetup(t *testing.T) {
+	g := NewGame()
+	runCommands(t, g, "create master", "set width 10", "set height 10", "start")
+	for cell := range g.shipCells {
+		if !g.IsValidCoordinate(cell.X, cell.Y) {
+			t.Errorf("Expected every ship cell on the 10x10 board, got %v", cell)
+		}
+	}
+	if len(g.shipCells) != 4+2*2+2*3+4 {
+		t.Errorf("Expected the whole fleet on the board, got %d ship cells", len(g.shipCells))
+	}
+}
+
+func TestMasterFleetFollowsPlacement(t *testing.T) {
+	g := NewGame()
+	runCommands(t, g, "create master", "set width 12", "set height 12", "set placement edge", "start")
+	for _, ship := range g.ships {
+		if edgePlacement(g, ship) != 0 {
+			t.Errorf("Expected ship %+v to touch the border", ship)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+module battleship
+
+go 1.22
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// runCommands runs protocol commands on g and stops the test at the first
+// one that is not answered with "ok".
+func runCommands(t testing.TB, g *Game, commands ...string) {
+	t.Helper()
+	for _, command := range commands {
+		if reply := g.HandleCommand(command); reply != "ok" {
+			t.Fatalf("%q: expected ok, got %s", command, reply)
+		}
+	}
+}
+
+// newClassicFleet sets up a game with the classic fleet of ten ships.
+func newClassicFleet(t testing.TB, width, height int) *Game {
+	g := NewGame()
+	runCommands(t, g,
+		fmt.Sprintf("set width %d", width),
+		fmt.Sprintf("set height %d", height),
+		"set count 1 4",
+		"set count 2 3",
+		"set count 3 2",
+		"set count 4 1",
+	)
+	return g
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	httpIdleTimeout   = 10 * time.Minute
+	httpSweepInterval = time.Minute
+	httpMaxBodySize   = 1 << 16
+)
+
+type hostedGame struct {
+	mutex    sync.Mutex
+	game     *Game
+	lastUsed time.Time
+}
+
+// GameServer hosts many games over HTTP. Every game has its own mutex, so
+// requests for different games run in parallel, and games that nobody has
+// touched for idleTimeout are dropped.
+type GameServer struct {
+	mutex       sync.Mutex
+	games       map[string]*hostedGame
+	idleTimeout time.Duration
+	now         func() time.Time
+}
+
+type apiRequest struct {
+	Role   string   `json:"role"`
+	Args   []string `json:"args"`
+	X      *int     `json:"x"`
+	Y      *int     `json:"y"`
+	Shots  []Pair   `json:"shots"`
+	Result string   `json:"result"`
+}
+
+type apiResponse struct {
+	ID      string `json:"id,omitempty"`
+	Reply   string `json:"reply,omitempty"`
+	X       *int   `json:"x,omitempty"`
+	Y       *int   `json:"y,omitempty"`
+	Shots   []Pair `json:"shots,omitempty"`
+	Outcome string `json:"outcome,omitempty"`
+	State   string `json:"state,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func NewGameServer(idleTimeout time.Duration) *GameServer {
+	return &GameServer{
+		games:       make(map[string]*hostedGame),
+		idleTimeout: idleTimeout,
+		now:         time.Now,
+	}
+}
+
+// Handler routes the game endpoints:
+//
+//	POST   /games              {"role": "master"}  -> {"id": "...", "reply": "ok"}
+//	DELETE /games/{id}
+//	POST   /games/{id}/set     {"args": ["width", "10"]}
+//	POST   /games/{id}/start
+//	POST   /games/{id}/shot    {"x": 3, "y": 4}, {"shots": [{"x": 3, "y": 4}, ...]} or {} for the bot shot
+//	POST   /games/{id}/result  {"result": "hit"} or {"result": "hit miss kill"} after a salvo
+//	GET    /games/{id}/finished
+//	GET    /games/{id}/dump
+//
+// A "failed" reply is returned with status 422.
+func (s *GameServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /games", s.handleCreate)
+	mux.HandleFunc("DELETE /games/{id}", s.handleDelete)
+	mux.HandleFunc("POST /games/{id}/set", s.gameHandler(func(g *Game, request apiRequest) apiResponse {
+		if len(request.Args) == 0 {
+			return apiResponse{Reply: "failed"}
+		}
+		return apiResponse{Reply: g.HandleCommand("set " + strings.Join(request.Args, " "))}
+	}))
+	mux.HandleFunc("POST /games/{id}/start", s.gameHandler(func(g *Game, request apiRequest) apiResponse {
+		return apiResponse{Reply: g.HandleCommand("start")}
+	}))
+	mux.HandleFunc("POST /games/{id}/shot", s.gameHandler(func(g *Game, request apiRequest) apiResponse {
+		if request.X != nil && request.Y != nil {
+			return apiResponse{Reply: g.HandleCommand(fmt.Sprintf("shot %d %d", *request.X, *request.Y))}
+		}
+		if len(request.Shots) > 0 {
+			return apiResponse{Reply: g.HandleCommand("shot " + Response{Shots: request.Shots}.String())}
+		}
+		response := g.Execute("shot")
+		if len(response.Shots) == 0 {
+			return apiResponse{Reply: response.String()}
+		}
+		reply := apiResponse{Reply: "ok", Shots: response.Shots}
+		if len(response.Shots) == 1 {
+			reply.X, reply.Y = &response.Shots[0].X, &response.Shots[0].Y
+		}
+		return reply
+	}))
+	mux.HandleFunc("POST /games/{id}/result", s.gameHandler(func(g *Game, request apiRequest) apiResponse {
+		return apiResponse{Reply: g.HandleCommand("set result " + request.Result)}
+	}))
+	mux.HandleFunc("GET /games/{id}/finished", s.gameHandler(func(g *Game, request apiRequest) apiResponse {
+		return apiResponse{Reply: g.HandleCommand("finished"), Outcome: g.Outcome()}
+	}))
+	mux.HandleFunc("GET /games/{id}/dump", s.gameHandler(func(g *Game, request apiRequest) apiResponse {
+		var state bytes.Buffer
+		if err := g.WriteState(&state); err != nil {
+			return apiResponse{Reply: "failed", Error: err.Error()}
+		}
+		return apiResponse{Reply: "ok", State: state.String()}
+	}))
+	return mux
+}
+
+func writeResponse(w http.ResponseWriter, status int, response apiResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// readRequest decodes the JSON body of a request, refusing bodies larger
+// than httpMaxBodySize.
+func readRequest(w http.ResponseWriter, r *http.Request) (apiRequest, error) {
+	var request apiRequest
+	if r.Body == nil || r.ContentLength == 0 {
+		return request, nil
+	}
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, httpMaxBodySize)).Decode(&request)
+	return request, err
+}
+
+func requestErrorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+func newGameID() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+func (s *GameServer) handleCreate(w http.ResponseWriter, r *http.Request) {
+	request, err := readRequest(w, r)
+	if err != nil {
+		writeResponse(w, requestErrorStatus(err), apiResponse{Error: err.Error()})
+		return
+	}
+	id, err := newGameID()
+	if err != nil {
+		writeResponse(w, http.StatusInternalServerError, apiResponse{Error: err.Error()})
+		return
+	}
+
+	g := NewGame()
+	if reply := g.HandleCommand("create " + request.Role); reply != "ok" {
+		writeResponse(w, http.StatusUnprocessableEntity, apiResponse{Reply: reply})
+		return
+	}
+
+	s.mutex.Lock()
+	s.games[id] = &hostedGame{game: g, lastUsed: s.now()}
+	s.mutex.Unlock()
+	writeResponse(w, http.StatusCreated, apiResponse{ID: id, Reply: "ok"})
+}
+
+func (s *GameServer) handleDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s.mutex.Lock()
+	_, ok := s.games[id]
+	delete(s.games, id)
+	s.mutex.Unlock()
+	if !ok {
+		writeResponse(w, http.StatusNotFound, apiResponse{Error: "Unknown game " + id})
+		return
+	}
+	writeResponse(w, http.StatusOK, apiResponse{Reply: "ok"})
+}
+
+func (s *GameServer) lookup(id string) *hostedGame {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.games[id]
+}
+
+// gameHandler runs an operation on the game named in the path while
+// holding that game's mutex.
+func (s *GameServer) gameHandler(operation func(g *Game, request apiRequest) apiResponse) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		hosted := s.lookup(id)
+		if hosted == nil {
+			writeResponse(w, http.StatusNotFound, apiResponse{Error: "Unknown game " + id})
+			return
+		}
+		request, err := readRequest(w, r)
+		if err != nil {
+			writeResponse(w, requestErrorStatus(err), apiResponse{Error: err.Error()})
+			return
+		}
+
+		hosted.mutex.Lock()
+		hosted.lastUsed = s.now()
+		response := operation(hosted.game, request)
+		hosted.mutex.Unlock()
+
+		status := http.StatusOK
+		if response.Reply == "failed" {
+			status = http.StatusUnprocessableEntity
+		}
+		writeResponse(w, status, response)
+	}
+}
+
+// ExpireIdle drops the games that have been idle for longer than the
+// server's idle timeout and returns how many were dropped.
+func (s *GameServer) ExpireIdle() int {
+	now := s.now()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	expired := 0
+	for id, hosted := range s.games {
+		// A game that is busy serving a request is not idle.
+		if !hosted.mutex.TryLock() {
+			continue
+		}
+		idle := now.Sub(hosted.lastUsed)
+		hosted.mutex.Unlock()
+		if idle > s.idleTimeout {
+			delete(s.games, id)
+			expired++
+		}
+	}
+	return expired
+}
+
+func (s *GameServer) GameCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.games)
+}
+
+// ListenAndServe serves the games on address until the listener fails,
+// sweeping idle games in the background.
+func (s *GameServer) ListenAndServe(address string) error {
+	ticker := time.NewTicker(httpSweepInterval)
+	defer ticker.Stop()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				s.ExpireIdle()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return http.ListenAndServe(address, s.Handler())
+}
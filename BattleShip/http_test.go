@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func callAPI(t *testing.T, server *httptest.Server, method, path string, body interface{}) (int, apiResponse) {
+	var payload bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&payload).Encode(body)
+	}
+	request, err := http.NewRequest(method, server.URL+path, &payload)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	reply, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer reply.Body.Close()
+	var response apiResponse
+	if err := json.NewDecoder(reply.Body).Decode(&response); err != nil {
+		t.Fatalf("%s %s: decoding reply: %v", method, path, err)
+	}
+	return reply.StatusCode, response
+}
+
+func TestHTTPGame(t *testing.T) {
+	games := NewGameServer(time.Minute)
+	server := httptest.NewServer(games.Handler())
+	defer server.Close()
+
+	status, created := callAPI(t, server, "POST", "/games", map[string]string{"role": "slave"})
+	if status != http.StatusCreated || created.ID == "" {
+		t.Fatalf("create: got %d %+v", status, created)
+	}
+	prefix := "/games/" + created.ID
+
+	if status, _ := callAPI(t, server, "POST", prefix+"/shot", map[string]int{"x": 0, "y": 0}); status != http.StatusUnprocessableEntity {
+		t.Errorf("Expected a shot before start to fail, got %d", status)
+	}
+	for _, args := range [][]string{{"width", "1"}, {"height", "1"}, {"count", "1", "1"}} {
+		if status, response := callAPI(t, server, "POST", prefix+"/set", map[string][]string{"args": args}); status != http.StatusOK {
+			t.Fatalf("set %v: got %d %+v", args, status, response)
+		}
+	}
+	if _, response := callAPI(t, server, "POST", prefix+"/start", nil); response.Reply != "ok" {
+		t.Fatalf("start: got %+v", response)
+	}
+
+	_, shot := callAPI(t, server, "POST", prefix+"/shot", struct{}{})
+	if shot.X == nil || shot.Y == nil || *shot.X != 0 || *shot.Y != 0 {
+		t.Fatalf("Expected the bot to fire at 0 0, got %+v", shot)
+	}
+	if _, response := callAPI(t, server, "POST", prefix+"/result", map[string]string{"result": "kill"}); response.Reply != "ok" {
+		t.Errorf("result: got %+v", response)
+	}
+	if _, response := callAPI(t, server, "GET", prefix+"/finished", nil); response.Reply != "yes" || response.Outcome != "win" {
+		t.Errorf("Expected the game to be won, got %+v", response)
+	}
+	if _, response := callAPI(t, server, "GET", prefix+"/dump", nil); !bytes.HasPrefix([]byte(response.State), []byte("battleship ")) {
+		t.Errorf("Expected a saved game, got %+v", response)
+	}
+
+	if status, _ := callAPI(t, server, "GET", "/games/missing/finished", nil); status != http.StatusNotFound {
+		t.Errorf("Expected an unknown game to give 404, got %d", status)
+	}
+	if status, _ := callAPI(t, server, "DELETE", prefix, nil); status != http.StatusOK || games.GameCount() != 0 {
+		t.Errorf("Expected the game to be deleted, got %d with %d games left", status, games.GameCount())
+	}
+}
+
+func TestHTTPExpiresIdleGames(t *testing.T) {
+	now := time.Unix(0, 0)
+	games := NewGameServer(time.Minute)
+	games.now = func() time.Time { return now }
+	server := httptest.NewServer(games.Handler())
+	defer server.Close()
+
+	_, first := callAPI(t, server, "POST", "/games", map[string]string{"role": "slave"})
+	now = now.Add(50 * time.Second)
+	callAPI(t, server, "POST", "/games", map[string]string{"role": "slave"})
+	now = now.Add(20 * time.Second)
+
+	if expired := games.ExpireIdle(); expired != 1 {
+		t.Errorf("Expected one idle game to expire, got %d", expired)
+	}
+	if status, _ := callAPI(t, server, "GET", "/games/"+first.ID+"/finished", nil); status != http.StatusNotFound {
+		t.Errorf("Expected the expired game to be gone, got %d", status)
+	}
+}
+
+func TestHTTPRejectsLargeBodies(t *testing.T) {
+	games := NewGameServer(time.Minute)
+	server := httptest.NewServer(games.Handler())
+	defer server.Close()
+
+	role := strings.Repeat("x", httpMaxBodySize)
+	if status, _ := callAPI(t, server, "POST", "/games", map[string]string{"role": role}); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected an oversized body to give 413, got %d", status)
+	}
+	if games.GameCount() != 0 {
+		t.Errorf("Expected no game to be created")
+	}
+}
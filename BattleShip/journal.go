@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Journal records a game as plain text lines: the seed, every ship
+// placement and bot shot as they happen, and each command with its result.
+type Journal struct {
+	out    io.Writer
+	closer io.Closer
+}
+
+func CreateJournal(path string) (*Journal, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{out: file, closer: file}, nil
+}
+
+func (j *Journal) Record(format string, args ...interface{}) {
+	fmt.Fprintf(j.out, format+"\n", args...)
+}
+
+func (j *Journal) Close() error {
+	if j.closer == nil {
+		return nil
+	}
+	return j.closer.Close()
+}
+
+func isUnrecordedCommand(commandLine string) bool {
+	fields := strings.Fields(commandLine)
+	if len(fields) == 0 {
+		return false
+	}
+	switch fields[0] {
+	case "journal", "replay", "show", "stats", "tournament":
+		return true
+	}
+	return false
+}
+
+func isReplaySkipped(command string) bool {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return false
+	}
+	switch fields[0] {
+	case "serve", "connect", "dump", "exit":
+		return true
+	}
+	return false
+}
+
+// ReplayJournal re-runs a recorded game on a fresh Game and checks that
+// every placement, shot and command result comes out the same.
+func ReplayJournal(path string) (*Game, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var buffer bytes.Buffer
+	game := NewGame()
+	game.journal = &Journal{out: &buffer}
+
+	expected := make([]string, 0)
+	recordedOutcome := ""
+	lineNumber := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		lineNumber++
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "seed ") {
+			seed, err := strconv.ParseInt(strings.TrimPrefix(line, "seed "), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Line %d: invalid seed: %v", lineNumber, err)
+			}
+			game.SetSeed(seed)
+			continue
+		}
+
+		if !strings.HasPrefix(line, "cmd ") {
+			if strings.HasPrefix(line, "outcome ") {
+				recordedOutcome = strings.TrimPrefix(line, "outcome ")
+			}
+			expected = append(expected, line)
+			continue
+		}
+
+		command, _, found := strings.Cut(strings.TrimPrefix(line, "cmd "), " -> ")
+		if !found {
+			return nil, fmt.Errorf("Line %d: invalid command record: %s", lineNumber, line)
+		}
+		if isReplaySkipped(command) {
+			expected = expected[:0]
+			continue
+		}
+
+		buffer.Reset()
+		game.HandleCommand(command)
+		produced := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+		expected = append(expected, line)
+		if len(produced) != len(expected) {
+			return nil, fmt.Errorf("Line %d: expected %q, got %q", lineNumber, expected, produced)
+		}
+		for i := range expected {
+			if produced[i] != expected[i] {
+				return nil, fmt.Errorf("Line %d: expected %q, got %q", lineNumber, expected[i], produced[i])
+			}
+		}
+		expected = expected[:0]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(expected) > 0 {
+		return nil, fmt.Errorf("Journal ends with unmatched events: %q", expected)
+	}
+	if recordedOutcome != "" && game.Outcome() != recordedOutcome {
+		return nil, fmt.Errorf("Expected outcome %s, got %s", recordedOutcome, game.Outcome())
+	}
+	game.journal = nil
+	return game, nil
+}
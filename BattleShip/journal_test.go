@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func recordJournalGame(t *testing.T, path string) *Game {
+	g := NewGame()
+	opponent := newClassicFleet(t, 10, 10)
+	opponent.SetSeed(3)
+	if err := opponent.RandomizeShipPlacement(); err != nil {
+		t.Fatalf("placement: %v", err)
+	}
+	opponent.gameStarted = true
+
+	commands := []string{
+		"journal " + path,
+		"set seed 42",
+		"create slave",
+		"set width 10",
+		"set height 10",
+		"set count 1 4",
+		"set count 2 3",
+		"set count 3 2",
+		"set count 4 1",
+		"set strategy density",
+		"start",
+	}
+	runCommands(t, g, commands...)
+
+	for !opponent.IsLoser() {
+		fields := strings.Fields(g.HandleCommand("shot"))
+		if len(fields) != 2 {
+			t.Fatalf("bot has no shot")
+		}
+		result := opponent.HandleCommand("shot " + fields[0] + " " + fields[1])
+		g.HandleCommand("set result " + result)
+		if result == "miss" {
+			shot := opponent.GetNextShot()
+			g.HandleCommand(fmt.Sprintf("shot %d %d", shot.X, shot.Y))
+		}
+	}
+
+	runCommands(t, g, "journal off")
+	return g
+}
+
+func TestReplayJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.journal")
+	recorded := recordJournalGame(t, path)
+
+	replayed, err := ReplayJournal(path)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if replayed.Outcome() != "win" || recorded.Outcome() != "win" {
+		t.Errorf("Expected both games to be won, got %s and %s", recorded.Outcome(), replayed.Outcome())
+	}
+	if recorded.HandleCommand("replay "+path) != "ok" {
+		t.Errorf("Expected replay command to succeed")
+	}
+}
+
+func TestReplayDetectsDivergence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.journal")
+	recordJournalGame(t, path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	tampered := strings.Replace(string(data), "cmd set seed 42 -> ok", "cmd set seed 43 -> ok", 1)
+	if err := os.WriteFile(path, []byte(tampered), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if _, err := ReplayJournal(path); err == nil {
+		t.Errorf("Expected replay with a different seed to diverge")
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 )
@@ -14,15 +15,71 @@ func main() {
 	for {
 		fmt.Print("> ")
 		command, err := reader.ReadString('\n')
-		if err != nil {
-			fmt.Println("Error reading input:", err)
-			continue
+		if err != nil && command == "" {
+			if err != io.EOF {
+				fmt.Println("Error reading input:", err)
+			}
+			return
 		}
 
-		command = strings.TrimSpace(command)
-		result := game.HandleCommand(command)
-		if result != "" {
+		response, ok := launch(game, strings.Fields(command))
+		if !ok {
+			response = game.Execute(strings.TrimSpace(command))
+		}
+		if result := response.String(); result != "" {
 			fmt.Println(result)
 		}
+		if response.Shutdown {
+			return
+		}
+	}
+}
+
+func listenAddress(address string) string {
+	if !strings.Contains(address, ":") {
+		return ":" + address
+	}
+	return address
+}
+
+// launch runs the commands that block until a match, a server or the
+// interactive board is over. It reports false for every other command.
+func launch(game *Game, fields []string) (Response, bool) {
+	if len(fields) == 0 {
+		return Response{}, false
+	}
+	args := fields[1:]
+	switch fields[0] {
+	case "serve":
+		if len(args) < 1 || game.role != MASTER {
+			return Failed(), true
+		}
+		winner, err := game.Serve(listenAddress(args[0]))
+		if err != nil {
+			return Failed(), true
+		}
+		if winner == MASTER {
+			return Reply("win"), true
+		}
+		return Reply("lose"), true
+	case "connect":
+		if len(args) < 1 || game.Connect(args[0]) != nil {
+			return Failed(), true
+		}
+		return Reply("ok"), true
+	case "http":
+		if len(args) < 1 {
+			return Failed(), true
+		}
+		if err := NewGameServer(httpIdleTimeout).ListenAndServe(listenAddress(args[0])); err != nil {
+			return Failed(), true
+		}
+		return Reply("ok"), true
+	case "tui":
+		if game.RunTUI(os.Stdin, os.Stdout) != nil {
+			return Failed(), true
+		}
+		return Reply("ok"), true
 	}
+	return Response{}, false
 }
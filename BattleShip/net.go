@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+type Player interface {
+	Send(command string) (string, error)
+}
+
+type LocalPlayer struct {
+	game *Game
+}
+
+func (p *LocalPlayer) Send(command string) (string, error) {
+	reply, _ := p.game.ExecuteRemote(command)
+	return reply, nil
+}
+
+type RemotePlayer struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func NewRemotePlayer(conn net.Conn) *RemotePlayer {
+	return &RemotePlayer{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+	}
+}
+
+func (p *RemotePlayer) Send(command string) (string, error) {
+	_, err := fmt.Fprintf(p.conn, "%s\n", command)
+	if err != nil {
+		return "", err
+	}
+	reply, err := p.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(reply), nil
+}
+
+// remoteSettings are the settings the other side of a match may change.
+var remoteSettings = map[string]bool{
+	"width":  true,
+	"height": true,
+	"count":  true,
+	"shape":  true,
+	"rule":   true,
+	"result": true,
+}
+
+// isRemoteCommand reports whether a peer is allowed to run the command.
+// Only the commands of the match protocol are accepted, so a peer can not
+// touch files, start servers or get replies that span several lines.
+func isRemoteCommand(fields []string) bool {
+	if len(fields) == 0 {
+		return false
+	}
+	switch fields[0] {
+	case "start", "shot", "finished", "exit":
+		return true
+	case "create":
+		return len(fields) == 2 && fields[1] == "slave"
+	case "set":
+		return len(fields) > 1 && remoteSettings[fields[1]]
+	}
+	return false
+}
+
+// ExecuteRemote handles a command received from the other side of a match
+// and reports whether it ended the session.
+func (g *Game) ExecuteRemote(commandLine string) (string, bool) {
+	if !isRemoteCommand(strings.Fields(commandLine)) {
+		return "failed", false
+	}
+	response := g.Execute(commandLine)
+	return response.String(), response.Shutdown
+}
+
+func (g *Game) Serve(address string) (Role, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return NONE, err
+	}
+	defer listener.Close()
+
+	conn, err := listener.Accept()
+	if err != nil {
+		return NONE, err
+	}
+	defer conn.Close()
+
+	return g.HostMatch(conn)
+}
+
+func (g *Game) Connect(address string) error {
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return g.ServeConn(conn)
+}
+
+func (g *Game) ServeConn(conn net.Conn) error {
+	reader := bufio.NewReader(conn)
+	for {
+		command, err := reader.ReadString('\n')
+		if err != nil {
+			if command == "" {
+				return nil
+			}
+			return err
+		}
+
+		reply, done := g.ExecuteRemote(strings.TrimSpace(command))
+		_, err = fmt.Fprintf(conn, "%s\n", reply)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+func (g *Game) HostMatch(conn net.Conn) (Role, error) {
+	if !g.gameStarted && g.HandleCommand("start") != "ok" {
+		return NONE, fmt.Errorf("Failed to start master game")
+	}
+
+	slave := NewRemotePlayer(conn)
+	setup := []string{
+		"create slave",
+		fmt.Sprintf("set width %d", g.width),
+		fmt.Sprintf("set height %d", g.height),
+	}
+	setup = append(setup, g.rules.RuleCommands()...)
+	for _, size := range g.ShipSizes() {
+		setup = append(setup, fmt.Sprintf("set count %d %d", size, g.shipCounts[size]))
+	}
+	for _, name := range sortedNames(g.shapeCounts) {
+		setup = append(setup, fmt.Sprintf("set shape %s %d", name, g.shapeCounts[name]))
+	}
+	setup = append(setup, "start")
+	for _, command := range setup {
+		reply, err := slave.Send(command)
+		if err != nil {
+			return NONE, err
+		}
+		if reply != "ok" {
+			return NONE, fmt.Errorf("Slave rejected %q: %s", command, reply)
+		}
+	}
+
+	winner, err := PlayMatch(&LocalPlayer{game: g}, slave, g.rules)
+	if err != nil {
+		return NONE, err
+	}
+	slave.Send("exit")
+	return winner, nil
+}
+
+// PlayMatch relays shots between the two players until one fleet is sunk.
+// The master fires first and, when the rules grant it, a player keeps the
+// turn after a hit or kill. A shot the target refuses (for example a
+// repeated cell) passes the turn. In salvo mode a player fires its whole
+// volley at once and the turn always passes, and a player that takes longer
+// than the move time limit to pick its shots loses the turn.
+func PlayMatch(master, slave Player, rules Rules) (Role, error) {
+	players := [2]Player{master, slave}
+	roles := [2]Role{MASTER, SLAVE}
+	turn := 0
+
+	for {
+		shooter := players[turn]
+		target := players[1-turn]
+
+		started := time.Now()
+		coordinates, err := shooter.Send("shot")
+		if err != nil {
+			return NONE, err
+		}
+		if rules.MoveTimeLimit > 0 && time.Since(started) > rules.MoveTimeLimit {
+			turn = 1 - turn
+			continue
+		}
+		values, err := atoiAll(strings.Fields(coordinates))
+		if err != nil || len(values) == 0 || len(values)%2 != 0 {
+			return NONE, fmt.Errorf("Player %d has no shot: %s", roles[turn], coordinates)
+		}
+
+		result, err := target.Send("shot " + strings.Join(strings.Fields(coordinates), " "))
+		if err != nil {
+			return NONE, err
+		}
+		if result == "failed" {
+			turn = 1 - turn
+			continue
+		}
+		reports, ok := ParseShotReports(strings.Fields(result))
+		if !ok || len(reports) != len(values)/2 {
+			return NONE, fmt.Errorf("Unexpected shot result: %s", result)
+		}
+
+		reply, err := shooter.Send("set result " + result)
+		if err != nil {
+			return NONE, err
+		}
+		if reply != "ok" {
+			return NONE, fmt.Errorf("Failed to report result %s", result)
+		}
+
+		hit, killed := false, false
+		for _, report := range reports {
+			hit = hit || report.Result != MISS
+			killed = killed || report.Result == KILL
+		}
+		if killed {
+			finished, err := target.Send("finished")
+			if err != nil {
+				return NONE, err
+			}
+			if finished == "yes" {
+				return roles[turn], nil
+			}
+		}
+		if rules.Salvo || !hit || !rules.ExtraTurnOnHit {
+			turn = 1 - turn
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newMatchGame(t *testing.T, role Role) *Game {
+	g := NewGame()
+	g.role = role
+	g.isGameCreated = true
+	commands := []string{
+		"set width 10",
+		"set height 10",
+		"set count 1 4",
+		"set count 2 3",
+		"set count 3 2",
+		"set count 4 1",
+		"set strategy ordered",
+	}
+	runCommands(t, g, commands...)
+	return g
+}
+
+func TestLoopbackMatch(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+
+	master := newMatchGame(t, MASTER)
+	slave := newMatchGame(t, NONE)
+
+	type outcome struct {
+		winner Role
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			done <- outcome{NONE, err}
+			return
+		}
+		defer conn.Close()
+		winner, err := master.HostMatch(conn)
+		done <- outcome{winner, err}
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	slaveDone := make(chan error, 1)
+	go func() {
+		slaveDone <- slave.ServeConn(conn)
+		conn.Close()
+	}()
+
+	var result outcome
+	select {
+	case result = <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("match did not finish")
+	}
+	if result.err != nil {
+		t.Fatalf("match failed: %v", result.err)
+	}
+	if err := <-slaveDone; err != nil {
+		t.Fatalf("slave failed: %v", err)
+	}
+
+	if slave.role != SLAVE || slave.width != 10 || slave.shipCounts[2] != 3 {
+		t.Errorf("slave was not configured by master")
+	}
+
+	winner, loser := master, slave
+	if result.winner == SLAVE {
+		winner, loser = slave, master
+	} else if result.winner != MASTER {
+		t.Fatalf("unexpected winner %d", result.winner)
+	}
+	if loser.HandleCommand("finished") != "yes" {
+		t.Errorf("loser fleet is not sunk")
+	}
+	if winner.HandleCommand("win") != "yes" || winner.HandleCommand("lose") != "no" {
+		t.Errorf("winner does not report the win")
+	}
+	if loser.HandleCommand("lose") != "yes" || loser.HandleCommand("win") != "no" {
+		t.Errorf("loser does not report the loss")
+	}
+}
+
+func TestRemoteCommandsAreRestricted(t *testing.T) {
+	g := NewGame()
+	path := filepath.Join(t.TempDir(), "victim.txt")
+	if err := os.WriteFile(path, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, command := range []string{"dump " + path, "load " + path, "show", "stats", "set strategy ordered", "create master", "tui"} {
+		if reply, done := g.ExecuteRemote(command); reply != "failed" || done {
+			t.Errorf("%q: expected failed, got %s", command, reply)
+		}
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "keep" {
+		t.Errorf("Expected the file to be left alone, got %q (%v)", data, err)
+	}
+
+	if reply, _ := g.ExecuteRemote("create slave"); reply != "ok" {
+		t.Errorf("Expected create slave to be accepted, got %s", reply)
+	}
+	if reply, done := g.ExecuteRemote("exit"); reply != "ok" || !done {
+		t.Errorf("Expected exit to end the session, got %s", reply)
+	}
+}
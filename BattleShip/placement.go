@@ -0,0 +1,697 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	placementScanLimit  = 1 << 14
+	placementSampleSize = 256
+	// placementSearchLimit bounds the candidates and cells the styled
+	// search looks at before the complete search takes over.
+	placementSearchLimit = 1 << 22
+	// placementMemoWindow and placementMemoLimit bound the states the
+	// complete search remembers as dead ends.
+	placementMemoWindow = 1 << 12
+	placementMemoLimit  = 1 << 20
+)
+
+// PlacementStyle scores a position for a ship while the fleet is being
+// placed. Positions with higher scores are tried first, equal scores are
+// tried in random order.
+type PlacementStyle func(g *Game, ship Ship) float64
+
+var placementStyles = make(map[string]PlacementStyle)
+
+func RegisterPlacement(name string, style PlacementStyle) {
+	if _, ok := placementStyles[name]; ok {
+		panic("placement " + name + " is registered twice")
+	}
+	placementStyles[name] = style
+}
+
+func init() {
+	RegisterPlacement("random", func(g *Game, ship Ship) float64 { return 0 })
+	RegisterPlacement("edge", edgePlacement)
+	RegisterPlacement("spread", spreadPlacement)
+	RegisterPlacement("anti-density", antiDensityPlacement)
+}
+
+func (g *Game) SetPlacement(name string) bool {
+	if _, ok := placementStyles[name]; !ok {
+		return false
+	}
+	g.placement = name
+	return true
+}
+
+// edgePlacement keeps ships close to the border of the field.
+func edgePlacement(g *Game, ship Ship) float64 {
+	low, high := ship.Bounds()
+	distance := min(low.X, low.Y, g.width-1-high.X, g.height-1-high.Y)
+	return -float64(distance)
+}
+
+// spreadPlacement keeps ships as far as possible from the ones already
+// placed.
+func spreadPlacement(g *Game, ship Ship) float64 {
+	if len(g.ships) == 0 {
+		return 0
+	}
+	low, high := ship.Bounds()
+	nearest := -1
+	for _, other := range g.ships {
+		otherLow, otherHigh := other.Bounds()
+		gapX := max(otherLow.X-high.X, low.X-otherHigh.X, 0)
+		gapY := max(otherLow.Y-high.Y, low.Y-otherHigh.Y, 0)
+		gap := max(gapX, gapY)
+		if nearest < 0 || gap < nearest {
+			nearest = gap
+		}
+	}
+	return float64(nearest)
+}
+
+// antiDensityPlacement prefers the cells that a density bot shooting at an
+// empty board considers least likely to hold a straight ship.
+func antiDensityPlacement(g *Game, ship Ship) float64 {
+	score := 0.0
+	for _, cell := range ship.Cells() {
+		for _, size := range g.ShipSizes() {
+			count := g.shipCounts[size]
+			score += float64(count * placementsThrough(cell.X, g.width, size))
+			if size > 1 {
+				score += float64(count * placementsThrough(cell.Y, g.height, size))
+			}
+		}
+	}
+	return -score
+}
+
+// placementsThrough counts the positions of a ship of the given size on a
+// line of length n that cover coordinate p.
+func placementsThrough(p, n, size int) int {
+	return max(0, min(p, n-size)-max(0, p-size+1)+1)
+}
+
+// placementKind is a group of identical ships and the range of candidate
+// positions they share.
+type placementKind struct {
+	left       int
+	first, end int
+	live       int
+}
+
+// fleetSearch places the fleet by backtracking over candidate positions.
+// A candidate is alive while it fits next to the ships placed so far and
+// has not been ruled out; the counters below follow the candidates as ships
+// come and go instead of enumerating them again at every step.
+type fleetSearch struct {
+	g          *Game
+	style      PlacementStyle
+	exhaustive bool
+	steps      int
+
+	kinds      []placementKind
+	candidates []Ship
+	kindOf     []int
+	conflicts  []int
+	covering   map[Pair][]int
+	blocked    map[Pair]int
+
+	// cover counts the live candidates through every cell of a board that
+	// is searched exhaustively; only covered cells can still hold a ship.
+	cover        []int
+	clique       []bool
+	freeCells    int
+	cellsNeeded  int
+	blocksNeeded int
+}
+
+// shipBlocks is the least number of 2x2 squares that cover a ship: one
+// holds at most two cells of a straight ship and four cells of a shape.
+func shipBlocks(ship Ship) int {
+	if ship.Shape != nil {
+		return (ship.Size + 3) / 4
+	}
+	return (ship.Size + 1) / 2
+}
+
+// newFleetSearch lists the candidate positions of every kind of ship. Small
+// boards are enumerated completely so that the search can prove there is no
+// layout; larger boards are sampled at random.
+func newFleetSearch(g *Game, fleet []FleetEntry) *fleetSearch {
+	s := &fleetSearch{
+		g:          g,
+		style:      placementStyles[g.placement],
+		exhaustive: int64(g.width)*int64(g.height) <= placementScanLimit,
+		covering:   make(map[Pair][]int),
+		blocked:    make(map[Pair]int),
+	}
+	if s.exhaustive {
+		s.cover = make([]int, g.width*g.height)
+		s.clique = make([]bool, g.width*g.height)
+	}
+	for _, entry := range fleet {
+		positions := g.fleetPositions(entry, s.exhaustive)
+		first := len(s.candidates)
+		for _, ship := range positions {
+			for _, cell := range ship.Cells() {
+				s.covering[cell] = append(s.covering[cell], len(s.candidates))
+			}
+			s.candidates = append(s.candidates, ship)
+			s.kindOf = append(s.kindOf, len(s.kinds))
+		}
+		s.kinds = append(s.kinds, placementKind{
+			left:  entry.Count,
+			first: first,
+			end:   len(s.candidates),
+		})
+		s.cellsNeeded += entry.Count * entry.Template.Size
+		s.blocksNeeded += entry.Count * shipBlocks(entry.Template)
+	}
+	s.conflicts = make([]int, len(s.candidates))
+	for i := range s.candidates {
+		s.revive(i)
+	}
+	return s
+}
+
+// fleetPositions lists the positions of a ship like entry.Template on the
+// empty board, or a random sample of them.
+func (g *Game) fleetPositions(entry FleetEntry, exhaustive bool) []Ship {
+	orientations := entry.Template.Orientations()
+
+	positions := make([]Ship, 0)
+	if exhaustive {
+		for y := 0; y < g.height; y++ {
+			for x := 0; x < g.width; x++ {
+				for _, ship := range orientations {
+					ship.X, ship.Y = x, y
+					if g.CanPlace(ship) {
+						positions = append(positions, ship)
+					}
+				}
+			}
+		}
+		return positions
+	}
+
+	seen := make(map[Ship]bool)
+	for i := 0; i < placementSampleSize*entry.Count; i++ {
+		ship := orientations[g.rng.Intn(len(orientations))]
+		ship.X = g.rng.Intn(g.width)
+		ship.Y = g.rng.Intn(g.height)
+		if !seen[ship] && g.CanPlace(ship) {
+			seen[ship] = true
+			positions = append(positions, ship)
+		}
+	}
+	return positions
+}
+
+func (s *fleetSearch) alive(i int) bool {
+	kind := &s.kinds[s.kindOf[i]]
+	return s.conflicts[i] == 0 && kind.left > 0
+}
+
+func (s *fleetSearch) revive(i int) {
+	s.kinds[s.kindOf[i]].live++
+	if !s.exhaustive {
+		return
+	}
+	for _, cell := range s.candidates[i].Cells() {
+		index := cell.Y*s.g.width + cell.X
+		s.cover[index]++
+		if s.cover[index] == 1 {
+			s.freeCells++
+		}
+	}
+}
+
+func (s *fleetSearch) kill(i int) {
+	s.kinds[s.kindOf[i]].live--
+	if !s.exhaustive {
+		return
+	}
+	for _, cell := range s.candidates[i].Cells() {
+		index := cell.Y*s.g.width + cell.X
+		s.cover[index]--
+		if s.cover[index] == 0 {
+			s.freeCells--
+		}
+	}
+}
+
+// footprint lists the cells a ship takes away from the others: its own and,
+// with the no-touch rule, their neighbours.
+func (g *Game) footprint(ship Ship) []Pair {
+	cells := ship.Cells()
+	if !g.rules.NoTouch {
+		return cells
+	}
+	seen := make(map[Pair]bool)
+	footprint := make([]Pair, 0)
+	for _, cell := range cells {
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				near := Pair{X: cell.X + dx, Y: cell.Y + dy}
+				if !seen[near] && g.IsValidCoordinate(near.X, near.Y) {
+					seen[near] = true
+					footprint = append(footprint, near)
+				}
+			}
+		}
+	}
+	return footprint
+}
+
+// exclude rules candidate i out until include is called for it.
+func (s *fleetSearch) exclude(i int) {
+	if s.alive(i) {
+		s.kill(i)
+	}
+	s.conflicts[i]++
+}
+
+func (s *fleetSearch) include(i int) {
+	s.conflicts[i]--
+	if s.alive(i) {
+		s.revive(i)
+	}
+}
+
+// add places the ship of candidate i; remove takes it back.
+func (s *fleetSearch) add(i int) {
+	kind := &s.kinds[s.kindOf[i]]
+	if kind.left == 1 {
+		for j := kind.first; j < kind.end; j++ {
+			if s.alive(j) {
+				s.kill(j)
+			}
+		}
+	}
+	kind.left--
+
+	ship := s.candidates[i]
+	for _, cell := range s.g.footprint(ship) {
+		s.blocked[cell]++
+		if s.blocked[cell] == 1 {
+			for _, j := range s.covering[cell] {
+				s.exclude(j)
+			}
+		}
+	}
+	s.g.addShip(ship)
+	s.cellsNeeded -= ship.Size
+	s.blocksNeeded -= shipBlocks(ship)
+}
+
+func (s *fleetSearch) remove(i int) {
+	ship := s.candidates[i]
+	s.cellsNeeded += ship.Size
+	s.blocksNeeded += shipBlocks(ship)
+	s.g.removeLastShip()
+	for _, cell := range s.g.footprint(ship) {
+		s.blocked[cell]--
+		if s.blocked[cell] == 0 {
+			for _, j := range s.covering[cell] {
+				s.include(j)
+			}
+		}
+	}
+
+	kind := &s.kinds[s.kindOf[i]]
+	kind.left++
+	if kind.left == 1 {
+		for j := kind.first; j < kind.end; j++ {
+			if s.alive(j) {
+				s.revive(j)
+			}
+		}
+	}
+}
+
+// feasible rejects boards that no longer have room for the ships left. With
+// the no-touch rule the free cells are split into groups that fit in a 2x2
+// square: the cells of a group touch each other, so every group holds part
+// of at most one ship.
+func (s *fleetSearch) feasible() bool {
+	if !s.exhaustive {
+		return true
+	}
+	if s.freeCells < s.cellsNeeded {
+		return false
+	}
+	if !s.g.rules.NoTouch {
+		return true
+	}
+
+	width, height := s.g.width, s.g.height
+	s.steps += width * height
+	clear(s.clique)
+	groups := 0
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			index := y*width + x
+			if s.cover[index] == 0 || s.clique[index] {
+				continue
+			}
+			groups++
+			for dy := 0; dy < 2 && y+dy < height; dy++ {
+				for dx := 0; dx < 2 && x+dx < width; dx++ {
+					s.clique[index+dy*width+dx] = true
+				}
+			}
+		}
+	}
+	return groups >= s.blocksNeeded
+}
+
+type placementOptions struct {
+	candidates []int
+	scores     []float64
+}
+
+func (o placementOptions) Len() int           { return len(o.candidates) }
+func (o placementOptions) Less(i, j int) bool { return o.scores[i] > o.scores[j] }
+func (o placementOptions) Swap(i, j int) {
+	o.candidates[i], o.candidates[j] = o.candidates[j], o.candidates[i]
+	o.scores[i], o.scores[j] = o.scores[j], o.scores[i]
+}
+
+// place tries the kind of ship with the fewest positions left first, its
+// positions in the order of the placement style. A position that leads to
+// no layout is ruled out for the rest of the step, so identical ships never
+// search the same layout twice.
+func (s *fleetSearch) place() bool {
+	if s.cellsNeeded == 0 {
+		return true
+	}
+	if s.steps > placementSearchLimit || !s.feasible() {
+		return false
+	}
+
+	var kind *placementKind
+	for i := range s.kinds {
+		if s.kinds[i].left > 0 && (kind == nil || s.kinds[i].live < kind.live) {
+			kind = &s.kinds[i]
+		}
+	}
+	if kind.live < kind.left {
+		return false
+	}
+
+	s.steps += kind.end - kind.first
+	options := make([]int, 0, kind.live)
+	for i := kind.first; i < kind.end; i++ {
+		if s.alive(i) {
+			options = append(options, i)
+		}
+	}
+	s.g.rng.Shuffle(len(options), func(i, j int) {
+		options[i], options[j] = options[j], options[i]
+	})
+	if !s.exhaustive && len(options) > placementSampleSize {
+		options = options[:placementSampleSize]
+	}
+	scores := make([]float64, len(options))
+	for i, option := range options {
+		scores[i] = s.style(s.g, s.candidates[option])
+	}
+	sort.Stable(placementOptions{options, scores})
+
+	found := false
+	tried := 0
+	for _, i := range options {
+		s.add(i)
+		if s.place() {
+			found = true
+			break
+		}
+		s.remove(i)
+		s.exclude(i)
+		tried++
+		if s.steps > placementSearchLimit || !s.feasible() || kind.live < kind.left {
+			break
+		}
+	}
+	for _, i := range options[:tried] {
+		s.include(i)
+	}
+	return found
+}
+
+// tileSearch is the complete search used when the styled one gives up. It
+// walks the board in row order and decides for the first undecided cell
+// whether one of the ships left starts there or the cell stays empty. Every
+// layout is reached this way exactly once, and the empty cells are counted
+// against the slack the fleet leaves on the board, so a tight fleet is cut
+// off as soon as it wastes a cell. States that led nowhere are remembered,
+// so two ways of filling the same rows are never searched twice.
+type tileSearch struct {
+	g      *Game
+	starts [][]Ship
+	left   []int
+	blocks []int
+	slack  int
+	empty  int
+	rows   int
+	// taken counts the ships that cover a cell or, with the no-touch rule,
+	// border it.
+	taken  map[int]int
+	failed map[string]bool
+}
+
+type tileOption struct {
+	ship Ship
+	kind int
+}
+
+func newTileSearch(g *Game, fleet []FleetEntry) *tileSearch {
+	t := &tileSearch{
+		g:      g,
+		slack:  g.width * g.height,
+		rows:   1,
+		taken:  make(map[int]int),
+		failed: make(map[string]bool),
+	}
+	for _, entry := range fleet {
+		// Every orientation is moved so that its first cell in row order
+		// lies on the origin.
+		starts := entry.Template.Orientations()
+		for i, ship := range starts {
+			low, high := ship.Bounds()
+			first := ship.Cell(0)
+			for _, cell := range ship.Cells() {
+				if cell.Y < first.Y || cell.Y == first.Y && cell.X < first.X {
+					first = cell
+				}
+			}
+			starts[i].X, starts[i].Y = ship.X-first.X, ship.Y-first.Y
+			t.rows = max(t.rows, high.Y-low.Y+1)
+		}
+		t.starts = append(t.starts, starts)
+		t.left = append(t.left, entry.Count)
+		t.blocks = append(t.blocks, shipBlocks(entry.Template))
+		t.slack -= entry.Count * entry.Template.Size
+	}
+	return t
+}
+
+func (t *tileSearch) done() bool {
+	for _, left := range t.left {
+		if left > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *tileSearch) index(cell Pair) int {
+	return cell.Y*t.g.width + cell.X
+}
+
+func (t *tileSearch) occupied(index int) bool {
+	_, ok := t.g.shipCells[Pair{X: index % t.g.width, Y: index / t.g.width}]
+	return ok
+}
+
+func (t *tileSearch) add(option tileOption) {
+	t.left[option.kind]--
+	t.g.addShip(option.ship)
+	for _, cell := range t.g.footprint(option.ship) {
+		t.taken[t.index(cell)]++
+	}
+}
+
+func (t *tileSearch) remove(option tileOption) {
+	for _, cell := range t.g.footprint(option.ship) {
+		index := t.index(cell)
+		if t.taken[index]--; t.taken[index] == 0 {
+			delete(t.taken, index)
+		}
+	}
+	t.g.removeLastShip()
+	t.left[option.kind]++
+}
+
+// key describes the state at a cell: the ships left and the cells around
+// it that earlier ships took. Narrow boards only, to keep the keys short.
+func (t *tileSearch) key(index int) (string, bool) {
+	width := t.g.width
+	if width*(t.rows+1) > placementMemoWindow {
+		return "", false
+	}
+	key := make([]byte, 0, 8*len(t.left)+8+width*(t.rows+1)/8)
+	for _, left := range t.left {
+		key = fmt.Appendf(key, "%d,", left)
+	}
+	key = fmt.Appendf(key, "%d:", index)
+	var bits byte
+	end := min(index+t.rows*width, width*t.g.height)
+	for i := max(0, index-width-1); i < end; i++ {
+		bits <<= 1
+		if t.occupied(i) {
+			bits |= 1
+		}
+		if i%8 == 7 {
+			key = append(key, bits)
+			bits = 0
+		}
+	}
+	return string(append(key, bits)), true
+}
+
+// roomy rejects a no-touch board whose cells from index on can no longer
+// hold the ships left, with the same 2x2 groups as fleetSearch.feasible.
+// The rows below the reach of the ships placed so far are still empty, so
+// their groups are counted without looking at them.
+func (t *tileSearch) roomy(index int) bool {
+	if !t.g.rules.NoTouch {
+		return true
+	}
+	needed := 0
+	for i, left := range t.left {
+		needed += left * t.blocks[i]
+	}
+	width, height := t.g.width, t.g.height
+	reach := min(height, index/width+t.rows+1)
+	groups := (width + 1) / 2 * ((height - reach + 1) / 2)
+	grouped := make([]bool, reach*width-index)
+	for i := index; i < reach*width && groups < needed; i++ {
+		if grouped[i-index] || t.taken[i] > 0 {
+			continue
+		}
+		groups++
+		for _, next := range []int{i, i + 1, i + width, i + width + 1} {
+			sameRow := next%width >= i%width
+			if next < reach*width && sameRow {
+				grouped[next-index] = true
+			}
+		}
+	}
+	return groups >= needed
+}
+
+// fill places the ships left on the cells from index on. Ships are tried
+// before leaving a cell empty, in random order.
+func (t *tileSearch) fill(index int) bool {
+	size := t.g.width * t.g.height
+	emptied := 0
+	visited := make([]string, 0)
+	for {
+		for index < size && t.occupied(index) {
+			index++
+		}
+		if t.done() {
+			return true
+		}
+		if index == size || !t.roomy(index) {
+			break
+		}
+		key, memo := t.key(index)
+		if memo && t.failed[key] {
+			break
+		}
+		if memo {
+			visited = append(visited, key)
+		}
+
+		origin := Pair{X: index % t.g.width, Y: index / t.g.width}
+		options := make([]tileOption, 0)
+		for kind, starts := range t.starts {
+			if t.left[kind] == 0 {
+				continue
+			}
+			for _, ship := range starts {
+				ship.X += origin.X
+				ship.Y += origin.Y
+				if t.g.CanPlace(ship) {
+					options = append(options, tileOption{ship, kind})
+				}
+			}
+		}
+		t.g.rng.Shuffle(len(options), func(i, j int) {
+			options[i], options[j] = options[j], options[i]
+		})
+		for _, option := range options {
+			t.add(option)
+			if t.fill(index + 1) {
+				return true
+			}
+			t.remove(option)
+		}
+
+		if t.empty == t.slack {
+			break
+		}
+		t.empty++
+		emptied++
+		index++
+	}
+	t.empty -= emptied
+	for _, key := range visited {
+		if len(t.failed) < placementMemoLimit {
+			t.failed[key] = true
+		}
+	}
+	return false
+}
+
+// RandomizeShipPlacement places the whole fleet in the selected placement
+// style. The styled search looks at every position on boards up to
+// placementScanLimit cells and at a random sample of them on larger ones;
+// when it gives up, the complete tileSearch decides, so a fleet is only
+// reported as impossible when no layout exists. Layouts found by tileSearch
+// ignore the style.
+func (g *Game) RandomizeShipPlacement() error {
+	g.ResetBoard()
+
+	fleet := g.Fleet()
+	totalShipCells := 0
+	for _, entry := range fleet {
+		totalShipCells += entry.Count * entry.Template.Size
+	}
+	if int64(totalShipCells) > int64(g.width)*int64(g.height) {
+		return fmt.Errorf("Error: not enough space for all ships")
+	}
+
+	search := newFleetSearch(g, fleet)
+	if !search.place() {
+		g.ResetBoard()
+		proven := search.exhaustive && search.steps <= placementSearchLimit
+		if proven || !newTileSearch(g, fleet).fill(0) {
+			g.ResetBoard()
+			return fmt.Errorf("Failed to place ships")
+		}
+	}
+
+	ships := g.ships
+	g.ResetBoard()
+	for _, ship := range ships {
+		g.Place(ship)
+	}
+	return nil
+}
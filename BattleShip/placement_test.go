@@ -0,0 +1,134 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlacementFillsTightBoard(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		g := NewGame()
+		g.SetSeed(int64(i))
+		g.width = 4
+		g.height = 4
+		g.shipCounts[4] = 4
+		if err := g.RandomizeShipPlacement(); err != nil {
+			t.Fatalf("seed %d: %v", i, err)
+		}
+		if len(g.shipCells) != 16 {
+			t.Errorf("Expected the board to be full, got %d ship cells", len(g.shipCells))
+		}
+	}
+}
+
+func TestPlacementReportsImpossibleFleet(t *testing.T) {
+	g := NewGame()
+	g.rules = ClassicRules()
+	g.width = 3
+	g.height = 3
+	g.shipCounts[2] = 3
+	if err := g.RandomizeShipPlacement(); err == nil {
+		t.Errorf("Expected three no-touch ships of size 2 not to fit on 3x3")
+	}
+	if len(g.ships) != 0 {
+		t.Errorf("Expected a failed placement to leave the board empty")
+	}
+}
+
+func TestPlacementStyles(t *testing.T) {
+	for _, style := range []string{"random", "edge", "spread", "anti-density"} {
+		g := newClassicFleet(t, 10, 10)
+		g.rules = ClassicRules()
+		if reply := g.HandleCommand("set placement " + style); reply != "ok" {
+			t.Fatalf("set placement %s: got %s", style, reply)
+		}
+		if err := g.RandomizeShipPlacement(); err != nil {
+			t.Fatalf("%s: %v", style, err)
+		}
+		if len(g.ships) != 10 {
+			t.Errorf("%s: expected 10 ships, got %d", style, len(g.ships))
+		}
+	}
+
+	g := newClassicFleet(t, 10, 10)
+	g.shipCounts = map[int]int{4: 1, 3: 2}
+	g.HandleCommand("set placement edge")
+	g.RandomizeShipPlacement()
+	for _, ship := range g.ships {
+		if edgePlacement(g, ship) != 0 {
+			t.Errorf("Expected ship %+v to touch the border", ship)
+		}
+	}
+
+	if g.HandleCommand("set placement nowhere") != "failed" {
+		t.Errorf("Expected an unknown placement to be rejected")
+	}
+}
+
+func TestPlacementFindsLatticeLayout(t *testing.T) {
+	for _, size := range []int{9, 19} {
+		g := NewGame()
+		g.rules = ClassicRules()
+		g.SetSeed(1)
+		g.width = size
+		g.height = size
+		g.shipCounts[1] = (size + 1) / 2 * ((size + 1) / 2)
+		if err := g.RandomizeShipPlacement(); err != nil {
+			t.Fatalf("%dx%d: %v", size, size, err)
+		}
+		for _, ship := range g.ships {
+			if ship.X%2 != 0 || ship.Y%2 != 0 {
+				t.Errorf("%dx%d: expected the only layout on even cells, got %+v", size, size, ship)
+			}
+		}
+	}
+}
+
+func TestPlacementRejectsOverfullBoardQuickly(t *testing.T) {
+	for _, size := range []int{20, 40, 64} {
+		g := NewGame()
+		g.rules = ClassicRules()
+		g.width = size
+		g.height = size
+		g.shipCounts[1] = size*size/4 + 1
+		start := time.Now()
+		if err := g.RandomizeShipPlacement(); err == nil {
+			t.Errorf("%dx%d: expected %d no-touch ships not to fit", size, size, g.shipCounts[1])
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%dx%d: proving the fleet does not fit took %v", size, size, elapsed)
+		}
+	}
+}
+
+func TestPlacementTilesBoard(t *testing.T) {
+	cases := []struct {
+		width, height int
+		size, count   int
+		noTouch       bool
+	}{
+		{10, 10, 2, 50, false},
+		{16, 16, 4, 64, false},
+		{12, 12, 3, 48, false},
+		{200, 1, 2, 100, false},
+		{128, 128, 1, 4096, true},
+	}
+	for _, c := range cases {
+		for seed := int64(0); seed < 3; seed++ {
+			g := NewGame()
+			g.SetSeed(seed)
+			g.rules.NoTouch = c.noTouch
+			g.rules.ShipSizes = []int{c.size}
+			g.width = c.width
+			g.height = c.height
+			g.shipCounts[c.size] = c.count
+			if err := g.RandomizeShipPlacement(); err != nil {
+				t.Errorf("%dx%d with %d ships of size %d, seed %d: %v", c.width, c.height, c.count, c.size, seed, err)
+				continue
+			}
+			if len(g.shipCells) != c.size*c.count {
+				t.Errorf("%dx%d: expected %d ship cells, got %d", c.width, c.height, c.size*c.count, len(g.shipCells))
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	showMaxColumns = 20
+	showMaxRows    = 20
+
+	colorReset   = "\x1b[0m"
+	colorShip    = "\x1b[32m"
+	colorMiss    = "\x1b[34m"
+	colorHit     = "\x1b[31m"
+	colorSunk    = "\x1b[35m"
+	colorReverse = "\x1b[7m"
+)
+
+type RenderOptions struct {
+	Colored bool
+	Fog     bool
+	Cursor  *Pair
+	Mark    *Pair
+}
+
+type renderedLine struct {
+	text  string
+	width int
+}
+
+func (g *Game) fleetCell(x, y int, fog bool) (byte, string) {
+	cell := Pair{X: x, Y: y}
+	index, isShip := g.shipCells[cell]
+	shot := g.shotCells[cell]
+	switch {
+	case isShip && shot && g.ships[index].Hits >= g.ships[index].Size:
+		return '#', colorSunk
+	case isShip && shot:
+		return 'X', colorHit
+	case shot:
+		return 'O', colorMiss
+	case isShip && !fog:
+		return 'S', colorShip
+	}
+	return '.', ""
+}
+
+func (g *Game) targetCell(x, y int) (byte, string) {
+	result, ok := g.target.Result(Pair{X: x, Y: y})
+	if !ok {
+		return '.', ""
+	}
+	switch result {
+	case HIT:
+		return 'X', colorHit
+	case KILL:
+		return '#', colorSunk
+	}
+	return 'O', colorMiss
+}
+
+// Viewport clamps the area that fits on screen so that it contains the
+// given cell, which keeps huge boards drawable.
+func (g *Game) Viewport(origin Pair) (Pair, int, int) {
+	columns := min(g.width, showMaxColumns)
+	rows := min(g.height, showMaxRows)
+	origin.X = max(0, min(origin.X, g.width-columns))
+	origin.Y = max(0, min(origin.Y, g.height-rows))
+	return origin, columns, rows
+}
+
+func (g *Game) renderBoard(title string, origin Pair, columns, rows int, cell func(x, y int) (byte, string), highlight *Pair, options RenderOptions) []renderedLine {
+	cellWidth := len(strconv.Itoa(origin.X+columns-1)) + 1
+	labelWidth := len(strconv.Itoa(origin.Y + rows - 1))
+	lineWidth := labelWidth + cellWidth*columns
+
+	lines := make([]renderedLine, 0, rows+2)
+	lines = append(lines, renderedLine{text: title, width: len(title)})
+
+	var header strings.Builder
+	header.WriteString(strings.Repeat(" ", labelWidth))
+	for x := origin.X; x < origin.X+columns; x++ {
+		label := strconv.Itoa(x)
+		header.WriteString(strings.Repeat(" ", cellWidth-len(label)))
+		header.WriteString(label)
+	}
+	lines = append(lines, renderedLine{text: header.String(), width: lineWidth})
+
+	for y := origin.Y; y < origin.Y+rows; y++ {
+		var line strings.Builder
+		label := strconv.Itoa(y)
+		line.WriteString(strings.Repeat(" ", labelWidth-len(label)))
+		line.WriteString(label)
+		for x := origin.X; x < origin.X+columns; x++ {
+			glyph, color := cell(x, y)
+			line.WriteString(strings.Repeat(" ", cellWidth-1))
+			if highlight != nil && highlight.X == x && highlight.Y == y {
+				if options.Colored {
+					color += colorReverse
+				} else {
+					glyph = '@'
+				}
+			}
+			if options.Colored && color != "" {
+				line.WriteString(color)
+				line.WriteByte(glyph)
+				line.WriteString(colorReset)
+			} else {
+				line.WriteByte(glyph)
+			}
+		}
+		lines = append(lines, renderedLine{text: line.String(), width: lineWidth})
+	}
+	return lines
+}
+
+// RenderBoards draws our fleet on the left and the opponent tracking board
+// on the right, both starting at origin.
+func (g *Game) RenderBoards(origin Pair, options RenderOptions) string {
+	if g.width <= 0 || g.height <= 0 {
+		return ""
+	}
+	origin, columns, rows := g.Viewport(origin)
+
+	fleet := g.renderBoard("Our fleet", origin, columns, rows, func(x, y int) (byte, string) {
+		return g.fleetCell(x, y, options.Fog)
+	}, options.Cursor, options)
+	target := g.renderBoard("Opponent", origin, columns, rows, g.targetCell, options.Mark, options)
+
+	leftWidth := 0
+	for _, line := range fleet {
+		leftWidth = max(leftWidth, line.width)
+	}
+
+	var out strings.Builder
+	for i := range fleet {
+		out.WriteString(fleet[i].text)
+		out.WriteString(strings.Repeat(" ", leftWidth-fleet[i].width+4))
+		out.WriteString(target[i].text)
+		if i < len(fleet)-1 {
+			out.WriteByte('\n')
+		}
+	}
+	return out.String()
+}
+
+func (g *Game) HandleShowCommand(args []string) string {
+	options := RenderOptions{Colored: true}
+	if len(args) > 0 && args[0] == "plain" {
+		options.Colored = false
+		args = args[1:]
+	}
+	origin := Pair{X: 0, Y: 0}
+	if len(args) >= 2 {
+		x, err1 := strconv.Atoi(args[0])
+		y, err2 := strconv.Atoi(args[1])
+		if err1 != nil || err2 != nil {
+			return "failed"
+		}
+		origin = Pair{X: x, Y: y}
+	}
+	board := g.RenderBoards(origin, options)
+	if board == "" {
+		return "failed"
+	}
+	return board
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShowRendersBothBoards(t *testing.T) {
+	g := NewGame()
+	g.width = 4
+	g.height = 3
+	g.PlaceShip(2, 0, 0, false)
+	g.PlaceShip(1, 3, 2, false)
+	g.gameStarted = true
+	g.HandleShotCommand(0, 0)
+	g.HandleShotCommand(3, 2)
+	g.HandleShotCommand(2, 1)
+	g.target.Mark(Pair{X: 1, Y: 1}, HIT)
+	g.target.Mark(Pair{X: 2, Y: 2}, MISS)
+
+	expected := strings.Join([]string{
+		"Our fleet    Opponent",
+		"  0 1 2 3      0 1 2 3",
+		"0 X S . .    0 . . . .",
+		"1 . . O .    1 . X . .",
+		"2 . . . #    2 . . O .",
+	}, "\n")
+	if board := g.HandleCommand("show plain"); board != expected {
+		t.Errorf("Expected board:\n%s\ngot:\n%s", expected, board)
+	}
+
+	colored := g.HandleCommand("show")
+	for _, color := range []string{colorHit, colorMiss, colorSunk, colorShip} {
+		if !strings.Contains(colored, color) {
+			t.Errorf("Expected colour %q in coloured board", color)
+		}
+	}
+}
+
+func TestShowViewportOnLargeBoard(t *testing.T) {
+	g := NewGame()
+	g.width = 75000
+	g.height = 75000
+	board := g.HandleCommand("show plain 74995 10")
+	lines := strings.Split(board, "\n")
+	if len(lines) != showMaxRows+2 {
+		t.Fatalf("Expected %d lines, got %d", showMaxRows+2, len(lines))
+	}
+	if !strings.Contains(lines[1], "74999") || !strings.HasPrefix(lines[2], "10") {
+		t.Errorf("Expected viewport clamped to the right edge starting at row 10, got:\n%s", board)
+	}
+}
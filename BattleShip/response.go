@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Status int
+
+const (
+	StatusOK Status = iota
+	StatusFailed
+)
+
+// Response is the outcome of one protocol command. Shots is set when the bot
+// fires, with one cell per shot of a salvo. Message holds every other reply
+// ("ok", "hit", "yes", "10", a rendered board). Shutdown asks the caller to
+// end the session.
+type Response struct {
+	Status   Status
+	Shots    []Pair
+	Message  string
+	Shutdown bool
+}
+
+// Reply wraps a plain text reply, treating "failed" as a failure.
+func Reply(message string) Response {
+	if message == "failed" {
+		return Failed()
+	}
+	return Response{Status: StatusOK, Message: message}
+}
+
+func Failed() Response {
+	return Response{Status: StatusFailed, Message: "failed"}
+}
+
+func ShotResponse(shots ...Pair) Response {
+	return Response{Status: StatusOK, Shots: shots}
+}
+
+// String formats the response as a line of the text protocol.
+func (r Response) String() string {
+	if r.Status == StatusFailed {
+		return "failed"
+	}
+	if len(r.Shots) > 0 {
+		parts := make([]string, len(r.Shots))
+		for i, shot := range r.Shots {
+			parts[i] = fmt.Sprintf("%d %d", shot.X, shot.Y)
+		}
+		return strings.Join(parts, " ")
+	}
+	return r.Message
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rules also select the game variants: in salvo mode every side fires one
+// shot per surviving ship, RevealSize reports "kill <size>" instead of
+// "kill", and MoveTimeLimit makes a player who answers too slowly lose the
+// turn. A zero MoveTimeLimit means no limit, and an empty ShipSizes allows
+// straight ships of any size.
+type Rules struct {
+	NoTouch        bool
+	ShipSizes      []int
+	ExtraTurnOnHit bool
+	Shapes         map[string]*Shape
+	Salvo          bool
+	RevealSize     bool
+	MoveTimeLimit  time.Duration
+}
+
+func DefaultRules() Rules {
+	return Rules{
+		NoTouch:        false,
+		ExtraTurnOnHit: true,
+	}
+}
+
+func ClassicRules() Rules {
+	rules := DefaultRules()
+	rules.NoTouch = true
+	return rules
+}
+
+func (r Rules) IsSizeAllowed(size int) bool {
+	if len(r.ShipSizes) == 0 {
+		return size >= 1
+	}
+	for _, allowed := range r.ShipSizes {
+		if allowed == size {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rules) SizesString() string {
+	if len(r.ShipSizes) == 0 {
+		return "any"
+	}
+	parts := make([]string, len(r.ShipSizes))
+	for i, size := range r.ShipSizes {
+		parts[i] = strconv.Itoa(size)
+	}
+	return strings.Join(parts, ",")
+}
+
+func ParseShipSizes(value string) ([]int, error) {
+	if value == "any" {
+		return nil, nil
+	}
+	sizes := make([]int, 0)
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(value, ",") {
+		size, err := strconv.Atoi(part)
+		if err != nil || size < 1 {
+			return nil, fmt.Errorf("Invalid ship size: %s", part)
+		}
+		if !seen[size] {
+			seen[size] = true
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Ints(sizes)
+	return sizes, nil
+}
+
+func parseSwitch(value string) (bool, bool) {
+	switch value {
+	case "on", "yes", "true", "1":
+		return true, true
+	case "off", "no", "false", "0":
+		return false, true
+	}
+	return false, false
+}
+
+func parseTimeLimit(value string) (time.Duration, bool) {
+	if value == "off" {
+		return 0, true
+	}
+	limit, err := time.ParseDuration(value)
+	if err != nil || limit <= 0 {
+		return 0, false
+	}
+	return limit, true
+}
+
+func formatTimeLimit(limit time.Duration) string {
+	if limit <= 0 {
+		return "off"
+	}
+	return limit.String()
+}
+
+func formatSwitch(value bool) string {
+	if value {
+		return "on"
+	}
+	return "off"
+}
+
+// RuleCommands returns the "set rule" commands that reproduce the rules on
+// another game.
+func (r Rules) RuleCommands() []string {
+	commands := []string{
+		"set rule notouch " + formatSwitch(r.NoTouch),
+		"set rule sizes " + r.SizesString(),
+		"set rule extraturn " + formatSwitch(r.ExtraTurnOnHit),
+		"set rule salvo " + formatSwitch(r.Salvo),
+		"set rule reveal " + formatSwitch(r.RevealSize),
+		"set rule timelimit " + formatTimeLimit(r.MoveTimeLimit),
+	}
+	names := make([]string, 0, len(r.Shapes))
+	for name := range r.Shapes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		commands = append(commands, "set rule shape "+name+" "+r.Shapes[name].String())
+	}
+	return commands
+}
+
+func (g *Game) HandleSetRuleCommand(args []string) string {
+	if len(args) < 2 || g.gameStarted {
+		return "failed"
+	}
+	switch args[0] {
+	case "preset":
+		switch args[1] {
+		case "default":
+			g.rules = DefaultRules()
+		case "classic":
+			g.rules = ClassicRules()
+		default:
+			return "failed"
+		}
+		return "ok"
+	case "notouch":
+		value, ok := parseSwitch(args[1])
+		if !ok {
+			return "failed"
+		}
+		g.rules.NoTouch = value
+		return "ok"
+	case "extraturn":
+		value, ok := parseSwitch(args[1])
+		if !ok {
+			return "failed"
+		}
+		g.rules.ExtraTurnOnHit = value
+		return "ok"
+	case "salvo":
+		value, ok := parseSwitch(args[1])
+		if !ok {
+			return "failed"
+		}
+		g.rules.Salvo = value
+		return "ok"
+	case "reveal":
+		value, ok := parseSwitch(args[1])
+		if !ok {
+			return "failed"
+		}
+		g.rules.RevealSize = value
+		return "ok"
+	case "timelimit":
+		limit, ok := parseTimeLimit(args[1])
+		if !ok {
+			return "failed"
+		}
+		g.rules.MoveTimeLimit = limit
+		return "ok"
+	case "sizes":
+		sizes, err := ParseShipSizes(args[1])
+		if err != nil {
+			return "failed"
+		}
+		g.rules.ShipSizes = sizes
+		return "ok"
+	case "shape":
+		if len(args) < 3 {
+			return "failed"
+		}
+		shape, err := ParseShape(args[1], args[2])
+		if err != nil {
+			return "failed"
+		}
+		// Rules are copied between games, so the map is never changed in
+		// place.
+		shapes := map[string]*Shape{shape.Name: shape}
+		for name, other := range g.rules.Shapes {
+			if name != shape.Name {
+				shapes[name] = other
+			}
+		}
+		g.rules.Shapes = shapes
+		return "ok"
+	}
+	return "failed"
+}
+
+func (g *Game) HandleGetRuleCommand(args []string) string {
+	if len(args) < 1 {
+		return "failed"
+	}
+	switch args[0] {
+	case "notouch":
+		return formatSwitch(g.rules.NoTouch)
+	case "extraturn":
+		return formatSwitch(g.rules.ExtraTurnOnHit)
+	case "salvo":
+		return formatSwitch(g.rules.Salvo)
+	case "reveal":
+		return formatSwitch(g.rules.RevealSize)
+	case "timelimit":
+		return formatTimeLimit(g.rules.MoveTimeLimit)
+	case "sizes":
+		return g.rules.SizesString()
+	case "shape":
+		if len(args) < 2 {
+			return "failed"
+		}
+		shape, ok := g.rules.Shape(args[1])
+		if !ok {
+			return "failed"
+		}
+		return shape.String()
+	}
+	return "failed"
+}
+
+func (g *Game) touchesShip(x, y int) bool {
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			if _, ok := g.shipCells[Pair{X: x + dx, Y: y + dy}]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
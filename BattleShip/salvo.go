@@ -0,0 +1,135 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ShotReport is the answer to one shot. Size is the size of the sunk ship
+// when the rules reveal it on a kill, 0 otherwise.
+type ShotReport struct {
+	Result ShotResult
+	Size   int
+}
+
+// ParseShotReports reads results such as "hit miss kill 3": every result
+// word may be followed by the size of the ship it sank.
+func ParseShotReports(fields []string) ([]ShotReport, bool) {
+	reports := make([]ShotReport, 0, len(fields))
+	for i := 0; i < len(fields); i++ {
+		result, ok := parseShotResult(fields[i])
+		if !ok {
+			return nil, false
+		}
+		report := ShotReport{Result: result}
+		if result == KILL && i+1 < len(fields) {
+			if size, err := strconv.Atoi(fields[i+1]); err == nil {
+				if size <= 0 {
+					return nil, false
+				}
+				report.Size = size
+				i++
+			}
+		}
+		reports = append(reports, report)
+	}
+	return reports, len(reports) > 0
+}
+
+// FleetAfloat counts our ships that have not been sunk yet.
+func (g *Game) FleetAfloat() int {
+	afloat := 0
+	for _, ship := range g.ships {
+		if ship.Hits < ship.Size {
+			afloat++
+		}
+	}
+	return afloat
+}
+
+// VolleySize is how many shots we fire per turn: one per surviving ship in
+// salvo mode, one otherwise.
+func (g *Game) VolleySize() int {
+	if !g.rules.Salvo {
+		return 1
+	}
+	return max(1, g.FleetAfloat())
+}
+
+// OpponentVolleySize is how many shots the opponent may fire at us per
+// turn. Both fleets are the same, so it follows from our own kills.
+func (g *Game) OpponentVolleySize() int {
+	if !g.rules.Salvo {
+		return 1
+	}
+	return max(1, g.TotalShipCount()-g.target.TotalSunk())
+}
+
+// GetNextVolley asks the strategy for up to count distinct shots. Cells
+// already planned are reserved on the tracking board so that the strategy
+// does not pick them twice.
+func (g *Game) GetNextVolley(count int) []Pair {
+	volley := make([]Pair, 0, count)
+	for len(volley) < count {
+		nextShot := g.strategy.NextShot(g)
+		if nextShot.X < 0 || nextShot.Y < 0 {
+			break
+		}
+		g.target.Reserve(nextShot)
+		volley = append(volley, nextShot)
+	}
+	g.target.ClearReserved()
+
+	if len(volley) == 0 {
+		return volley
+	}
+	g.lastShot = volley[len(volley)-1]
+	g.pendingShots = volley
+	if g.journal != nil {
+		for _, shot := range volley {
+			g.journal.Record("fire %d %d", shot.X, shot.Y)
+		}
+	}
+	return volley
+}
+
+// ProcessShotReports applies the answers to the pending shots in the order
+// they were fired. Without pending shots a single report is accepted and
+// only remembered as the last result.
+func (g *Game) ProcessShotReports(reports []ShotReport) bool {
+	expected := max(1, len(g.pendingShots))
+	if len(reports) != expected {
+		return false
+	}
+	for i, report := range reports {
+		g.lastShotResult = report.Result
+		if i < len(g.pendingShots) {
+			cell := g.pendingShots[i]
+			g.target.MarkRevealed(cell, report.Result, report.Size)
+			g.strategy.OnResult(g, cell, report.Result)
+		}
+	}
+	g.pendingShots = nil
+	return true
+}
+
+// HandleVolleyCommand answers several shots at once. The whole volley is
+// rejected when it is too large or any of its shots is invalid.
+func (g *Game) HandleVolleyCommand(cells []Pair) string {
+	if !g.gameStarted || !g.rules.Salvo || len(cells) > g.OpponentVolleySize() {
+		return "failed"
+	}
+	seen := make(map[Pair]bool)
+	for _, cell := range cells {
+		if !g.IsValidCoordinate(cell.X, cell.Y) || g.shotCells[cell] || seen[cell] {
+			return "failed"
+		}
+		seen[cell] = true
+	}
+
+	results := make([]string, len(cells))
+	for i, cell := range cells {
+		results[i] = g.HandleShotCommand(cell.X, cell.Y)
+	}
+	return strings.Join(results, " ")
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseShotReports(t *testing.T) {
+	reports, ok := ParseShotReports(strings.Fields("hit miss kill 3 kill"))
+	expected := []ShotReport{{Result: HIT}, {Result: MISS}, {Result: KILL, Size: 3}, {Result: KILL}}
+	if !ok || len(reports) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, reports)
+	}
+	for i := range expected {
+		if reports[i] != expected[i] {
+			t.Errorf("Report %d: expected %v, got %v", i, expected[i], reports[i])
+		}
+	}
+	for _, invalid := range []string{"", "3", "hit 2", "kill 0", "sunk"} {
+		if _, ok := ParseShotReports(strings.Fields(invalid)); ok {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestVolleysAreDistinct(t *testing.T) {
+	for _, strategy := range StrategyNames() {
+		g := newClassicFleet(t, 10, 10)
+		g.rules.Salvo = true
+		g.SetSeed(3)
+		g.SetStrategy(strategy)
+		if err := g.RandomizeShipPlacement(); err != nil {
+			t.Fatalf("placement: %v", err)
+		}
+		g.gameStarted = true
+
+		volley := g.GetNextVolley(g.VolleySize())
+		if len(volley) != 10 {
+			t.Fatalf("%s: expected a volley of 10 shots, got %v", strategy, volley)
+		}
+		seen := make(map[Pair]bool)
+		for _, shot := range volley {
+			if seen[shot] {
+				t.Errorf("%s: %v is fired twice in %v", strategy, shot, volley)
+			}
+			seen[shot] = true
+		}
+		if g.target.ReservedCount() != 0 {
+			t.Errorf("%s: expected the reservations to be cleared", strategy)
+		}
+		if reply := g.HandleCommand("set result miss"); reply != "failed" {
+			t.Errorf("%s: expected a single result for a volley to fail, got %s", strategy, reply)
+		}
+		if reply := g.HandleCommand("set result" + strings.Repeat(" miss", 10)); reply != "ok" {
+			t.Errorf("%s: expected the volley results to be accepted, got %s", strategy, reply)
+		}
+		if len(g.target.Shots()) != 10 {
+			t.Errorf("%s: expected 10 marked cells, got %d", strategy, len(g.target.Shots()))
+		}
+	}
+}
+
+func TestVolleyShrinksWithFleet(t *testing.T) {
+	g := NewGame()
+	g.isGameCreated = true
+	g.width = 4
+	g.height = 1
+	g.shipCounts[1] = 2
+	g.rules.Salvo = true
+	g.PlaceShip(1, 0, 0, false)
+	g.PlaceShip(1, 2, 0, false)
+	g.allShipsPlaced = true
+	g.HandleCommand("start")
+
+	if size := g.VolleySize(); size != 2 {
+		t.Errorf("Expected a volley of 2 shots, got %d", size)
+	}
+	if reply := g.HandleCommand("shot 0 0 1 0"); reply != "kill miss" {
+		t.Errorf("Expected kill miss, got %s", reply)
+	}
+	if size := g.VolleySize(); size != 1 {
+		t.Errorf("Expected a volley of 1 shot after a loss, got %d", size)
+	}
+
+	g.target.Mark(Pair{X: 3, Y: 0}, KILL)
+	if size := g.OpponentVolleySize(); size != 1 {
+		t.Errorf("Expected the opponent to fire 1 shot, got %d", size)
+	}
+	if reply := g.HandleCommand("shot 2 0 3 0"); reply != "failed" {
+		t.Errorf("Expected an oversized volley to fail, got %s", reply)
+	}
+}
+
+func TestRevealedKillOfTouchingShips(t *testing.T) {
+	board := NewTargetBoard()
+	board.Mark(Pair{X: 0, Y: 0}, HIT)
+	board.Mark(Pair{X: 1, Y: 0}, HIT)
+	board.MarkRevealed(Pair{X: 2, Y: 0}, KILL, 1)
+
+	if board.SunkCount(1) != 1 || board.SunkCount(3) != 0 {
+		t.Errorf("Expected a sunk ship of size 1, got %v", board.sunk)
+	}
+	for _, cell := range []Pair{{X: 0, Y: 0}, {X: 1, Y: 0}} {
+		if result, _ := board.Result(cell); result != HIT {
+			t.Errorf("Expected %v to stay a hit, got %v", cell, result)
+		}
+	}
+}
+
+func TestSaveKeepsPendingVolley(t *testing.T) {
+	g := newClassicFleet(t, 10, 10)
+	g.isGameCreated = true
+	g.rules.Salvo = true
+	g.SetSeed(5)
+	runCommands(t, g, "start")
+	volley := g.GetNextVolley(g.VolleySize())
+
+	path := filepath.Join(t.TempDir(), "game.txt")
+	runCommands(t, g, "dump "+path)
+	loaded := NewGame()
+	runCommands(t, loaded, "load "+path)
+	if len(loaded.pendingShots) != len(volley) {
+		t.Fatalf("Expected %d pending shots, got %v", len(volley), loaded.pendingShots)
+	}
+	if reply := loaded.HandleCommand("set result" + strings.Repeat(" miss", len(volley))); reply != "ok" {
+		t.Errorf("Expected the loaded game to accept the volley results, got %s", reply)
+	}
+}
+
+func TestSalvoTournament(t *testing.T) {
+	rules := ClassicRules()
+	rules.Salvo = true
+	rules.RevealSize = true
+	config := TournamentConfig{
+		Games:      10,
+		Strategies: []string{"ordered", "custom", "density"},
+		Width:      10,
+		Height:     10,
+		Counts:     map[int]int{1: 4, 2: 3, 3: 2, 4: 1},
+		Rules:      rules,
+		Seed:       9,
+	}
+	results, err := RunTournament(config)
+	if err != nil {
+		t.Fatalf("tournament: %v", err)
+	}
+	for _, result := range results {
+		for i := range result.FirstShots {
+			if result.FirstShots[i] > 100 || result.SecondShots[i] > 100 {
+				t.Errorf("%s: game %d fired more shots than there are cells", result, i)
+			}
+		}
+	}
+}
+
+func TestOrderedRefiresDiscardedVolley(t *testing.T) {
+	g := newClassicFleet(t, 10, 10)
+	g.rules.Salvo = true
+	g.SetSeed(1)
+	g.SetStrategy("ordered")
+	if err := g.RandomizeShipPlacement(); err != nil {
+		t.Fatalf("placement: %v", err)
+	}
+	g.gameStarted = true
+
+	first := g.GetNextVolley(3)
+	// The volley ran out of time and was never answered.
+	again := g.GetNextVolley(3)
+	for i, cell := range []Pair{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}} {
+		if first[i] != cell || again[i] != cell {
+			t.Fatalf("Expected the discarded volley to be fired again, got %v and %v", first, again)
+		}
+	}
+	if reply := g.HandleCommand("set result miss miss miss"); reply != "ok" {
+		t.Fatalf("Expected the volley results to be accepted, got %s", reply)
+	}
+	if next := g.GetNextVolley(3); next[0] != (Pair{X: 3, Y: 0}) {
+		t.Errorf("Expected the answered cells to be skipped, got %v", next)
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	saveFormatHeader  = "battleship"
+	saveFormatVersion = 2
+)
+
+// WriteState saves the whole game in the versioned format:
+//
+//	battleship 2
+//	size 10 10
+//	rule notouch off
+//	count 4 1
+//	ship 4 h 3 5
+//	shot 3 6
+//	target 5 5 hit
+//
+// Every line after the header is a keyword followed by its values. Hits on
+// our ships are not stored, they are recomputed from the shot lines.
+func (g *Game) WriteState(out io.Writer) error {
+	writer := bufio.NewWriter(out)
+	fmt.Fprintf(writer, "%s %d\n", saveFormatHeader, saveFormatVersion)
+	fmt.Fprintf(writer, "size %d %d\n", g.width, g.height)
+	fmt.Fprintf(writer, "role %s\n", g.role)
+	fmt.Fprintf(writer, "created %s\n", formatSwitch(g.isGameCreated))
+	fmt.Fprintf(writer, "started %s\n", formatSwitch(g.gameStarted))
+	fmt.Fprintf(writer, "placed %s\n", formatSwitch(g.allShipsPlaced))
+	fmt.Fprintf(writer, "strategy %s\n", g.strategyName)
+	fmt.Fprintf(writer, "placement %s\n", g.placement)
+	fmt.Fprintf(writer, "seed %d %d\n", g.seed, g.source.draws)
+	for _, command := range g.rules.RuleCommands() {
+		fmt.Fprintf(writer, "%s\n", strings.TrimPrefix(command, "set "))
+	}
+	for _, size := range sortedKeys(g.shipCounts) {
+		fmt.Fprintf(writer, "count %d %d\n", size, g.shipCounts[size])
+	}
+	for _, name := range sortedNames(g.shapeCounts) {
+		fmt.Fprintf(writer, "shape %s %d\n", name, g.shapeCounts[name])
+	}
+
+	for _, ship := range g.ships {
+		fmt.Fprintf(writer, "ship %s\n", ship.Placement())
+	}
+	for _, shot := range g.shotHistory {
+		fmt.Fprintf(writer, "shot %d %d\n", shot.X, shot.Y)
+	}
+
+	for _, cell := range g.target.Shots() {
+		fmt.Fprintf(writer, "target %d %d %s\n", cell.X, cell.Y, g.target.cells[cell])
+	}
+	for _, size := range sortedKeys(g.target.sunk) {
+		fmt.Fprintf(writer, "sunk %d %d\n", size, g.target.sunk[size])
+	}
+	for _, signature := range sortedNames(g.target.sunkShapes) {
+		fmt.Fprintf(writer, "sunkshape %s %d\n", signature, g.target.sunkShapes[signature])
+	}
+	if state, ok := g.strategy.(StrategyState); ok {
+		for _, line := range state.SaveState() {
+			fmt.Fprintf(writer, "%s\n", line)
+		}
+	}
+	for _, shot := range g.pendingShots {
+		fmt.Fprintf(writer, "pending %d %d\n", shot.X, shot.Y)
+	}
+	fmt.Fprintf(writer, "last %d %d %s %s\n", g.lastShot.X, g.lastShot.Y, g.lastShotResult, formatSwitch(len(g.pendingShots) > 0))
+
+	return writer.Flush()
+}
+
+// ReadState loads a game written by WriteState. The header line has already
+// been consumed by LoadFromFile and is passed in as fields.
+func (g *Game) ReadState(header []string, scanner *bufio.Scanner) error {
+	if len(header) != 2 || header[1] != strconv.Itoa(saveFormatVersion) {
+		return fmt.Errorf("Unsupported save format: %s", strings.Join(header, " "))
+	}
+
+	state := NewGame()
+	started := false
+	shots := make([]Pair, 0)
+	lineNumber := 1
+	for scanner.Scan() {
+		line := scanner.Text()
+		lineNumber++
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		err := state.readStateLine(fields, &started, &shots)
+		if err != nil {
+			return fmt.Errorf("Line %d: %v", lineNumber, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if state.width <= 0 || state.height <= 0 {
+		return fmt.Errorf("Invalid field dimensions: %dx%d", state.width, state.height)
+	}
+	for _, shot := range shots {
+		if !state.IsValidCoordinate(shot.X, shot.Y) || state.shotCells[shot] {
+			return fmt.Errorf("Invalid shot at (%d, %d)", shot.X, shot.Y)
+		}
+		state.shotCells[shot] = true
+		state.shotHistory = append(state.shotHistory, shot)
+		if index, ok := state.shipCells[shot]; ok {
+			state.ships[index].Hits++
+		}
+	}
+	state.gameStarted = started
+
+	journal := g.journal
+	*g = *state
+	g.journal = journal
+	return nil
+}
+
+func (g *Game) readStateLine(fields []string, started *bool, shots *[]Pair) error {
+	values, err := atoiAll(fields[1:])
+	switch fields[0] {
+	case "size":
+		if err != nil || len(values) != 2 {
+			return fmt.Errorf("Invalid size")
+		}
+		g.width, g.height = values[0], values[1]
+	case "role":
+		if len(fields) != 2 {
+			return fmt.Errorf("Invalid role")
+		}
+		switch fields[1] {
+		case "master":
+			g.role = MASTER
+		case "slave":
+			g.role = SLAVE
+		default:
+			g.role = NONE
+		}
+	case "created", "started", "placed":
+		if len(fields) != 2 {
+			return fmt.Errorf("Invalid %s flag", fields[0])
+		}
+		value, ok := parseSwitch(fields[1])
+		if !ok {
+			return fmt.Errorf("Invalid %s flag", fields[0])
+		}
+		switch fields[0] {
+		case "created":
+			g.isGameCreated = value
+		case "started":
+			*started = value
+		case "placed":
+			g.allShipsPlaced = value
+		}
+	case "strategy":
+		if len(fields) != 2 || !g.SetStrategy(fields[1]) {
+			return fmt.Errorf("Invalid strategy")
+		}
+	case "placement":
+		if len(fields) != 2 || !g.SetPlacement(fields[1]) {
+			return fmt.Errorf("Invalid placement")
+		}
+	case "seed":
+		if len(fields) != 3 {
+			return fmt.Errorf("Invalid seed")
+		}
+		seed, err1 := strconv.ParseInt(fields[1], 10, 64)
+		draws, err2 := strconv.ParseUint(fields[2], 10, 64)
+		if err1 != nil || err2 != nil {
+			return fmt.Errorf("Invalid seed")
+		}
+		if err := g.RestoreSeed(seed, draws); err != nil {
+			return err
+		}
+	case "rule":
+		if g.HandleSetRuleCommand(fields[1:]) != "ok" {
+			return fmt.Errorf("Invalid rule")
+		}
+	case "count":
+		if err != nil || len(values) != 2 || values[1] < 0 {
+			return fmt.Errorf("Invalid count")
+		}
+		g.shipCounts[values[0]] = values[1]
+	case "shape":
+		if len(fields) != 3 {
+			return fmt.Errorf("Invalid shape count")
+		}
+		count, err := strconv.Atoi(fields[2])
+		if _, ok := g.rules.Shape(fields[1]); !ok || err != nil || count < 0 {
+			return fmt.Errorf("Invalid shape count")
+		}
+		g.shapeCounts[fields[1]] = count
+	case "ship":
+		ship, err := g.rules.ParsePlacement(fields[1:])
+		if err != nil {
+			return err
+		}
+		if (ship.Shape == nil && !g.rules.IsSizeAllowed(ship.Size)) || !g.CanPlace(ship) {
+			return fmt.Errorf("Cannot place ship %s", ship.Placement())
+		}
+		g.Place(ship)
+	case "shot":
+		if err != nil || len(values) != 2 {
+			return fmt.Errorf("Invalid shot")
+		}
+		*shots = append(*shots, Pair{X: values[0], Y: values[1]})
+	case "target":
+		if len(fields) != 4 {
+			return fmt.Errorf("Invalid target")
+		}
+		values, err := atoiAll(fields[1:3])
+		result, ok := parseShotResult(fields[3])
+		if err != nil || !ok {
+			return fmt.Errorf("Invalid target")
+		}
+		cell := Pair{X: values[0], Y: values[1]}
+		if _, ok := g.target.cells[cell]; !ok {
+			g.target.order = append(g.target.order, cell)
+		}
+		g.target.cells[cell] = result
+	case "sunk":
+		if err != nil || len(values) != 2 {
+			return fmt.Errorf("Invalid sunk count")
+		}
+		g.target.sunk[values[0]] = values[1]
+	case "sunkshape":
+		if len(fields) != 3 {
+			return fmt.Errorf("Invalid sunk shape")
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return fmt.Errorf("Invalid sunk shape")
+		}
+		g.target.sunkShapes[fields[1]] = count
+	case "pending":
+		if err != nil || len(values) != 2 {
+			return fmt.Errorf("Invalid pending shot")
+		}
+		g.pendingShots = append(g.pendingShots, Pair{X: values[0], Y: values[1]})
+	case "last":
+		if len(fields) != 5 {
+			return fmt.Errorf("Invalid last shot")
+		}
+		values, err := atoiAll(fields[1:3])
+		result, ok := parseShotResult(fields[3])
+		_, ok2 := parseSwitch(fields[4])
+		if err != nil || !ok || !ok2 {
+			return fmt.Errorf("Invalid last shot")
+		}
+		g.lastShot = Pair{X: values[0], Y: values[1]}
+		g.lastShotResult = result
+	default:
+		if state, ok := g.strategy.(StrategyState); ok {
+			if handled, err := state.LoadState(fields); handled {
+				return err
+			}
+		}
+		return fmt.Errorf("Unknown keyword %s", fields[0])
+	}
+	return nil
+}
+
+func parseShotResult(value string) (ShotResult, bool) {
+	switch value {
+	case "miss":
+		return MISS, true
+	case "hit":
+		return HIT, true
+	case "kill":
+		return KILL, true
+	}
+	return MISS, false
+}
+
+func atoiAll(fields []string) ([]int, error) {
+	values := make([]int, len(fields))
+	for i, field := range fields {
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func sortedKeys(counts map[int]int) []int {
+	keys := make([]int, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func sortedNames(counts map[string]int) []string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveResumesInterruptedGame(t *testing.T) {
+	g := newClassicFleet(t, 10, 10)
+	g.isGameCreated = true
+	g.role = SLAVE
+	g.SetSeed(11)
+	g.HandleCommand("set strategy custom")
+	runCommands(t, g, "start")
+
+	opponent := newClassicFleet(t, 10, 10)
+	opponent.SetSeed(12)
+	opponent.RandomizeShipPlacement()
+	opponent.gameStarted = true
+	for i := 0; i < 30; i++ {
+		shot := g.GetNextShot()
+		g.ProcessShotResult(opponent.HandleShotCommand(shot.X, shot.Y))
+		g.HandleShotCommand(i%10, i/10)
+	}
+	g.GetNextShot()
+
+	path := filepath.Join(t.TempDir(), "game.txt")
+	runCommands(t, g, "dump "+path)
+	loaded := NewGame()
+	runCommands(t, loaded, "load "+path)
+
+	var before, after bytes.Buffer
+	g.WriteState(&before)
+	loaded.WriteState(&after)
+	if before.String() != after.String() {
+		t.Fatalf("Expected identical state after load:\n%s\ngot:\n%s", before.String(), after.String())
+	}
+	if loaded.HandleCommand("get strategy") != "custom" || !loaded.gameStarted {
+		t.Errorf("Expected strategy and started flag to survive the load")
+	}
+	if loaded.ships[0].Hits != g.ships[0].Hits {
+		t.Errorf("Expected ship hits to be restored")
+	}
+
+	for i := 0; i < 20; i++ {
+		result := "miss"
+		if i%3 == 0 {
+			result = "hit"
+		}
+		g.ProcessShotResult(result)
+		loaded.ProcessShotResult(result)
+		if a, b := g.GetNextShot(), loaded.GetNextShot(); a != b {
+			t.Fatalf("Shot %d diverged after load: %v vs %v", i, a, b)
+		}
+	}
+}
+
+func TestLoadLegacyFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.txt")
+	legacy := strings.Join([]string{"10 8", "4 h 0 0", "2 v 9 5", ""}, "\n")
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	g := NewGame()
+	if err := g.LoadFromFile(path); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if g.width != 10 || g.height != 8 || len(g.ships) != 2 {
+		t.Errorf("Expected 10x8 board with 2 ships, got %dx%d with %d", g.width, g.height, len(g.ships))
+	}
+	if g.shipCounts[4] != 1 || g.shipCounts[2] != 1 {
+		t.Errorf("Expected ship counts to be rebuilt from the legacy file")
+	}
+}
+
+func TestLoadRejectsOtherVersions(t *testing.T) {
+	for _, header := range []string{"battleship 1", "battleship 3", "battleship x"} {
+		path := filepath.Join(t.TempDir(), "game.txt")
+		os.WriteFile(path, []byte(header+"\nsize 10 10\n"), 0644)
+		if err := NewGame().LoadFromFile(path); err == nil {
+			t.Errorf("Expected %q to be rejected", header)
+		}
+	}
+}
+
+func TestLoadRejectsHugeSeedDraws(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.txt")
+	os.WriteFile(path, []byte("battleship 2\nsize 10 10\nseed 1 18446744073709551615\n"), 0644)
+	if err := NewGame().LoadFromFile(path); err == nil {
+		t.Errorf("Expected a save asking for 2^64 draws to be rejected")
+	}
+}
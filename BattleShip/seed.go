@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// maxSeedDraws bounds the draws a saved game may ask to skip, since they
+// are replayed one by one.
+const maxSeedDraws = 1 << 26
+
+// countingSource remembers how many values were drawn from the seeded
+// source so that a saved game can continue the same random sequence.
+type countingSource struct {
+	source rand.Source64
+	draws  uint64
+}
+
+func newCountingSource(seed int64) *countingSource {
+	return &countingSource{source: rand.NewSource(seed).(rand.Source64)}
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.source.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.draws++
+	return s.source.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.source.Seed(seed)
+	s.draws = 0
+}
+
+func (g *Game) SetSeed(seed int64) {
+	g.seed = seed
+	g.source = newCountingSource(seed)
+	g.rng = rand.New(g.source)
+}
+
+// RestoreSeed seeds the game and skips the values a saved game had already
+// drawn.
+func (g *Game) RestoreSeed(seed int64, draws uint64) error {
+	if draws > maxSeedDraws {
+		return fmt.Errorf("Too many random draws: %d", draws)
+	}
+	g.SetSeed(seed)
+	for g.source.draws < draws {
+		g.source.Uint64()
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Shape is a polyomino ship. Its cells are offsets from the top left corner
+// of its bounding box, listed once for every distinct rotation.
+type Shape struct {
+	Name      string
+	Rotations [][]Pair
+	Signature string
+}
+
+var builtinShapes = map[string]*Shape{
+	"L":      NewShape("L", []Pair{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: 2}, {X: 1, Y: 2}}),
+	"T":      NewShape("T", []Pair{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 1, Y: 1}}),
+	"square": NewShape("square", []Pair{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}}),
+}
+
+func NewShape(name string, cells []Pair) *Shape {
+	shape := &Shape{Name: name}
+	seen := make(map[string]bool)
+	current := normalizeCells(cells)
+	for i := 0; i < 4; i++ {
+		key := formatCells(current)
+		if !seen[key] {
+			seen[key] = true
+			shape.Rotations = append(shape.Rotations, current)
+		}
+		rotated := make([]Pair, len(current))
+		for j, cell := range current {
+			rotated[j] = Pair{X: -cell.Y, Y: cell.X}
+		}
+		current = normalizeCells(rotated)
+	}
+	shape.Signature = cellsSignature(cells)
+	return shape
+}
+
+func (s *Shape) Size() int {
+	return len(s.Rotations[0])
+}
+
+func (s *Shape) String() string {
+	return formatCells(s.Rotations[0])
+}
+
+// normalizeCells moves cells so that their bounding box starts at 0 0 and
+// sorts them by row.
+func normalizeCells(cells []Pair) []Pair {
+	minX, minY := cells[0].X, cells[0].Y
+	for _, cell := range cells {
+		minX = min(minX, cell.X)
+		minY = min(minY, cell.Y)
+	}
+	normalized := make([]Pair, len(cells))
+	for i, cell := range cells {
+		normalized[i] = Pair{X: cell.X - minX, Y: cell.Y - minY}
+	}
+	sort.Slice(normalized, func(i, j int) bool {
+		if normalized[i].Y != normalized[j].Y {
+			return normalized[i].Y < normalized[j].Y
+		}
+		return normalized[i].X < normalized[j].X
+	})
+	return normalized
+}
+
+func formatCells(cells []Pair) string {
+	parts := make([]string, len(cells))
+	for i, cell := range cells {
+		parts[i] = fmt.Sprintf("%d,%d", cell.X, cell.Y)
+	}
+	return strings.Join(parts, "/")
+}
+
+// cellsSignature names a set of cells the same way whatever its position and
+// rotation, which lets the tracking board tell sunk ships apart.
+func cellsSignature(cells []Pair) string {
+	best := ""
+	current := normalizeCells(cells)
+	for i := 0; i < 4; i++ {
+		key := formatCells(current)
+		if best == "" || key < best {
+			best = key
+		}
+		rotated := make([]Pair, len(current))
+		for j, cell := range current {
+			rotated[j] = Pair{X: -cell.Y, Y: cell.X}
+		}
+		current = normalizeCells(rotated)
+	}
+	return best
+}
+
+func lineSignature(size int) string {
+	cells := make([]Pair, size)
+	for i := range cells {
+		cells[i] = Pair{X: i, Y: 0}
+	}
+	return cellsSignature(cells)
+}
+
+// ParseShape reads cell offsets written as "0,0/0,1/1,1". The cells must be
+// distinct and connected.
+func ParseShape(name, value string) (*Shape, error) {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return nil, fmt.Errorf("Invalid shape name: %s", name)
+	}
+	cells := make([]Pair, 0)
+	seen := make(map[Pair]bool)
+	for _, part := range strings.Split(value, "/") {
+		coordinates := strings.Split(part, ",")
+		if len(coordinates) != 2 {
+			return nil, fmt.Errorf("Invalid shape cell: %s", part)
+		}
+		x, err1 := strconv.Atoi(coordinates[0])
+		y, err2 := strconv.Atoi(coordinates[1])
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("Invalid shape cell: %s", part)
+		}
+		cell := Pair{X: x, Y: y}
+		if seen[cell] {
+			return nil, fmt.Errorf("Duplicate shape cell: %s", part)
+		}
+		seen[cell] = true
+		cells = append(cells, cell)
+	}
+
+	reached := map[Pair]bool{cells[0]: true}
+	stack := []Pair{cells[0]}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, next := range []Pair{
+			{X: current.X + 1, Y: current.Y},
+			{X: current.X - 1, Y: current.Y},
+			{X: current.X, Y: current.Y + 1},
+			{X: current.X, Y: current.Y - 1},
+		} {
+			if seen[next] && !reached[next] {
+				reached[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	if len(reached) != len(cells) {
+		return nil, fmt.Errorf("Shape %s is not connected", name)
+	}
+	return NewShape(name, cells), nil
+}
+
+// Shape finds a shape by name, custom shapes first.
+func (r Rules) Shape(name string) (*Shape, bool) {
+	if shape, ok := r.Shapes[name]; ok {
+		return shape, true
+	}
+	shape, ok := builtinShapes[name]
+	return shape, ok
+}
+
+// FleetEntry is one kind of ship in the fleet: a straight ship of a size or
+// a shape, and how many of them there are.
+type FleetEntry struct {
+	Template Ship
+	Count    int
+}
+
+// ShipSizes lists the sizes of straight ships in play: the ones the sizes
+// rule allows or, without that rule, every size that was given a count.
+func (g *Game) ShipSizes() []int {
+	if len(g.rules.ShipSizes) > 0 {
+		return g.rules.ShipSizes
+	}
+	return sortedKeys(g.shipCounts)
+}
+
+// Fleet lists the ships to place, straight ones by size and then shapes by
+// name.
+func (g *Game) Fleet() []FleetEntry {
+	fleet := make([]FleetEntry, 0)
+	for _, size := range g.ShipSizes() {
+		if g.shipCounts[size] > 0 {
+			fleet = append(fleet, FleetEntry{Template: Ship{Size: size}, Count: g.shipCounts[size]})
+		}
+	}
+	names := make([]string, 0, len(g.shapeCounts))
+	for name := range g.shapeCounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		shape, ok := g.rules.Shape(name)
+		if ok && g.shapeCounts[name] > 0 {
+			fleet = append(fleet, FleetEntry{Template: Ship{Size: shape.Size(), Shape: shape}, Count: g.shapeCounts[name]})
+		}
+	}
+	return fleet
+}
+
+func (g *Game) TotalShipCount() int {
+	total := 0
+	for _, entry := range g.Fleet() {
+		total += entry.Count
+	}
+	return total
+}
+
+// Orientations returns the ship turned every distinct way, anchored at 0 0.
+func (s Ship) Orientations() []Ship {
+	if s.Shape != nil {
+		ships := make([]Ship, len(s.Shape.Rotations))
+		for i := range ships {
+			ships[i] = Ship{Size: s.Size, Shape: s.Shape, Rotation: i}
+		}
+		return ships
+	}
+	if s.Size == 1 {
+		return []Ship{{Size: 1}}
+	}
+	return []Ship{{Size: s.Size}, {Size: s.Size, IsVertical: true}}
+}
+
+func (s Ship) Signature() string {
+	if s.Shape != nil {
+		return s.Shape.Signature
+	}
+	return lineSignature(s.Size)
+}
+
+// Bounds returns the top left and bottom right cells the ship covers.
+func (s Ship) Bounds() (Pair, Pair) {
+	cells := s.Cells()
+	low, high := cells[0], cells[0]
+	for _, cell := range cells {
+		low = Pair{X: min(low.X, cell.X), Y: min(low.Y, cell.Y)}
+		high = Pair{X: max(high.X, cell.X), Y: max(high.Y, cell.Y)}
+	}
+	return low, high
+}
+
+// Placement describes where the ship lies: "4 h 3 5" for a straight ship of
+// size 4, "L 2 3 5" for an L in its third rotation.
+func (s Ship) Placement() string {
+	if s.Shape != nil {
+		return fmt.Sprintf("%s %d %d %d", s.Shape.Name, s.Rotation, s.X, s.Y)
+	}
+	orientation := "h"
+	if s.IsVertical {
+		orientation = "v"
+	}
+	return fmt.Sprintf("%d %s %d %d", s.Size, orientation, s.X, s.Y)
+}
+
+// ParsePlacement reads the fields written by Placement.
+func (r Rules) ParsePlacement(fields []string) (Ship, error) {
+	if len(fields) != 4 {
+		return Ship{}, fmt.Errorf("Invalid ship")
+	}
+	position, err := atoiAll(fields[2:])
+	if err != nil {
+		return Ship{}, fmt.Errorf("Invalid ship")
+	}
+	ship := Ship{X: position[0], Y: position[1]}
+	if size, err := strconv.Atoi(fields[0]); err == nil {
+		ship.Size = size
+		ship.IsVertical = fields[1] == "v"
+		return ship, nil
+	}
+
+	shape, ok := r.Shape(fields[0])
+	if !ok {
+		return Ship{}, fmt.Errorf("Unknown shape %s", fields[0])
+	}
+	rotation, err := strconv.Atoi(fields[1])
+	if err != nil || rotation < 0 || rotation >= len(shape.Rotations) {
+		return Ship{}, fmt.Errorf("Invalid rotation of shape %s", shape.Name)
+	}
+	ship.Size = shape.Size()
+	ship.Shape = shape
+	ship.Rotation = rotation
+	return ship, nil
+}
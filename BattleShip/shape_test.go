@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func newShapedFleet(t testing.TB, seed int64) *Game {
+	g := NewGame()
+	g.SetSeed(seed)
+	g.isGameCreated = true
+	g.role = SLAVE
+	commands := []string{
+		"set width 8",
+		"set height 8",
+		"set rule notouch on",
+		"set rule shape Z 0,0/1,0/1,1/2,1",
+		"set count 2 1",
+		"set shape L 1",
+		"set shape T 1",
+		"set shape square 1",
+		"set shape Z 1",
+	}
+	runCommands(t, g, commands...)
+	return g
+}
+
+func TestShapeRotations(t *testing.T) {
+	counts := map[string]int{"L": 4, "T": 4, "square": 1}
+	for name, count := range counts {
+		shape := builtinShapes[name]
+		if len(shape.Rotations) != count {
+			t.Errorf("Expected %s to have %d rotations, got %d", name, count, len(shape.Rotations))
+		}
+		for rotation := range shape.Rotations {
+			ship := Ship{Size: shape.Size(), Shape: shape, Rotation: rotation, X: 3, Y: 3}
+			if cellsSignature(ship.Cells()) != shape.Signature {
+				t.Errorf("Expected rotation %d of %s to keep its signature", rotation, name)
+			}
+		}
+	}
+	if _, err := ParseShape("gap", "0,0/2,0"); err == nil {
+		t.Errorf("Expected a disconnected shape to be rejected")
+	}
+}
+
+func TestShapedShipsAreSunkByEveryCell(t *testing.T) {
+	g := newShapedFleet(t, 3)
+	runCommands(t, g, "start")
+	if len(g.ships) != 5 || len(g.shipCells) != 18 {
+		t.Fatalf("Expected 5 ships on 18 cells, got %d on %d", len(g.ships), len(g.shipCells))
+	}
+
+	kills := 0
+	for _, ship := range g.ships {
+		cells := ship.Cells()
+		for i, cell := range cells {
+			result := g.HandleCommand(fmt.Sprintf("shot %d %d", cell.X, cell.Y))
+			expected := "hit"
+			if i == len(cells)-1 {
+				expected = "kill"
+				kills++
+			}
+			if result != expected {
+				t.Errorf("Shot %v at %s: expected %s, got %s", cell, ship.Placement(), expected, result)
+			}
+		}
+	}
+	if kills != 5 || !g.IsLoser() {
+		t.Errorf("Expected the whole shaped fleet to be sunk")
+	}
+}
+
+func TestDensityStrategySinksShapedFleet(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		defender := newShapedFleet(t, seed)
+		defender.HandleCommand("start")
+		attacker := newShapedFleet(t, seed+100)
+		attacker.HandleCommand("set strategy density")
+		attacker.HandleCommand("start")
+
+		shots := 0
+		for !defender.IsLoser() {
+			shot := attacker.GetNextShot()
+			if shot.X < 0 || shots > 64 {
+				t.Fatalf("seed %d: density ran out of shots after %d", seed, shots)
+			}
+			shots++
+			attacker.HandleCommand("set result " + defender.HandleShotCommand(shot.X, shot.Y))
+		}
+		if !attacker.IsWinner() {
+			t.Errorf("seed %d: expected the attacker to know it has sunk every shape", seed)
+		}
+	}
+}
+
+func TestSaveRestoresShapes(t *testing.T) {
+	g := newShapedFleet(t, 5)
+	g.HandleCommand("start")
+	g.HandleCommand(fmt.Sprintf("shot %d %d", g.ships[1].X, g.ships[1].Y))
+
+	var before, after bytes.Buffer
+	g.WriteState(&before)
+	scanner := bufio.NewScanner(strings.NewReader(before.String()))
+	scanner.Scan()
+	loaded := NewGame()
+	if err := loaded.ReadState(strings.Fields(scanner.Text()), scanner); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	loaded.WriteState(&after)
+	if before.String() != after.String() {
+		t.Fatalf("Expected identical state after load:\n%s\ngot:\n%s", before.String(), after.String())
+	}
+	if loaded.HandleCommand("get shape Z") != "1" || loaded.HandleCommand("get rule shape Z") != "0,0/1,0/1,1/2,1" {
+		t.Errorf("Expected the custom shape to survive the load")
+	}
+}
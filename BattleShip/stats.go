@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const heatmapImageSize = 512
+
+// ShotStats sums up one side's shooting.
+type ShotStats struct {
+	Shots             int
+	Hits              int
+	Kills             int
+	LongestMissStreak int
+}
+
+func shotStats(shots []Pair, isHit func(Pair) bool, kills int) ShotStats {
+	stats := ShotStats{Shots: len(shots), Kills: kills}
+	streak := 0
+	for _, shot := range shots {
+		if isHit(shot) {
+			stats.Hits++
+			streak = 0
+			continue
+		}
+		streak++
+		stats.LongestMissStreak = max(stats.LongestMissStreak, streak)
+	}
+	return stats
+}
+
+// OurStats covers the shots we fired at the opponent.
+func (g *Game) OurStats() ShotStats {
+	return shotStats(g.target.Shots(), func(cell Pair) bool {
+		result, _ := g.target.Result(cell)
+		return result != MISS
+	}, g.target.TotalSunk())
+}
+
+// TheirStats covers the shots the opponent fired at our fleet.
+func (g *Game) TheirStats() ShotStats {
+	kills := 0
+	for _, ship := range g.ships {
+		if ship.Hits >= ship.Size {
+			kills++
+		}
+	}
+	return shotStats(g.shotHistory, func(cell Pair) bool {
+		_, ok := g.shipCells[cell]
+		return ok
+	}, kills)
+}
+
+func (s ShotStats) String() string {
+	hitRate := 0.0
+	if s.Shots > 0 {
+		hitRate = 100 * float64(s.Hits) / float64(s.Shots)
+	}
+	perKill := "no kills"
+	if s.Kills > 0 {
+		perKill = fmt.Sprintf("%.1f shots per kill", float64(s.Shots)/float64(s.Kills))
+	}
+	return fmt.Sprintf("%d shots, %d hits (%.1f%%), %d kills, %s, longest miss streak %d",
+		s.Shots, s.Hits, hitRate, s.Kills, perKill, s.LongestMissStreak)
+}
+
+// Heatmap counts shots and hits per cell of a board. Scale is the count
+// drawn in full color; larger counts are clipped to it.
+type Heatmap struct {
+	Width, Height int
+	Scale         int
+	Shots         map[Pair]int
+	Hits          map[Pair]int
+}
+
+func NewHeatmap(width, height int) *Heatmap {
+	return &Heatmap{
+		Width:  width,
+		Height: height,
+		Scale:  1,
+		Shots:  make(map[Pair]int),
+		Hits:   make(map[Pair]int),
+	}
+}
+
+// ShotHeatmap builds the heatmap of the opponent's shots at our fleet.
+func (g *Game) ShotHeatmap() *Heatmap {
+	heatmap := NewHeatmap(g.width, g.height)
+	for _, shot := range g.shotHistory {
+		heatmap.Shots[shot]++
+		if _, ok := g.shipCells[shot]; ok {
+			heatmap.Hits[shot]++
+		}
+	}
+	return heatmap
+}
+
+// WriteCSV writes one "x,y,shots,hits" row for every cell that was shot at.
+func (h *Heatmap) WriteCSV(out io.Writer) error {
+	cells := make([]Pair, 0, len(h.Shots))
+	for cell := range h.Shots {
+		cells = append(cells, cell)
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].Y != cells[j].Y {
+			return cells[i].Y < cells[j].Y
+		}
+		return cells[i].X < cells[j].X
+	})
+
+	writer := bufio.NewWriter(out)
+	fmt.Fprintln(writer, "x,y,shots,hits")
+	for _, cell := range cells {
+		fmt.Fprintf(writer, "%d,%d,%d,%d\n", cell.X, cell.Y, h.Shots[cell], h.Hits[cell])
+	}
+	return writer.Flush()
+}
+
+// heatmapScale fits the board into heatmapImageSize pixels: small boards
+// draw every cell as a square of pixels, large ones merge blocks of cells
+// into one pixel.
+func (h *Heatmap) heatmapScale() (int, int) {
+	side := max(h.Width, h.Height)
+	if side <= heatmapImageSize {
+		return 1, heatmapImageSize / side
+	}
+	return (side + heatmapImageSize - 1) / heatmapImageSize, 1
+}
+
+// blockShares returns, for every block of cells, the largest count of any
+// cell in it relative to the scale of the heatmap.
+func (h *Heatmap) blockShares(counts map[Pair]int, cellsPerPixel int) map[Pair]float64 {
+	blocks := make(map[Pair]int)
+	for cell, count := range counts {
+		block := Pair{X: cell.X / cellsPerPixel, Y: cell.Y / cellsPerPixel}
+		blocks[block] = max(blocks[block], count)
+	}
+	scale := max(1, h.Scale)
+	shares := make(map[Pair]float64, len(blocks))
+	for block, count := range blocks {
+		shares[block] = min(1, float64(count)/float64(scale))
+	}
+	return shares
+}
+
+func blendColor(share float64, full [3]byte) [3]byte {
+	const background = 24
+	var color [3]byte
+	for i := range color {
+		color[i] = byte(background + share*(float64(full[i])-background))
+	}
+	return color
+}
+
+// WriteBMP draws the shot heatmap on the left and the hit heatmap on the
+// right as a 24-bit BMP image. Colors are relative to the scale, so images
+// drawn with the same scale can be compared.
+func (h *Heatmap) WriteBMP(out io.Writer) error {
+	if h.Width <= 0 || h.Height <= 0 {
+		return fmt.Errorf("Field size is not set")
+	}
+	cellsPerPixel, pixelsPerCell := h.heatmapScale()
+	panelWidth := (h.Width + cellsPerPixel - 1) / cellsPerPixel * pixelsPerCell
+	panelHeight := (h.Height + cellsPerPixel - 1) / cellsPerPixel * pixelsPerCell
+	const gap = 4
+	width := 2*panelWidth + gap
+	height := panelHeight
+	rowSize := (width*3 + 3) / 4 * 4
+
+	const headerSize = 14 + 40
+	header := make([]byte, headerSize)
+	header[0], header[1] = 'B', 'M'
+	binary.LittleEndian.PutUint32(header[2:], uint32(headerSize+rowSize*height))
+	binary.LittleEndian.PutUint32(header[10:], headerSize)
+	binary.LittleEndian.PutUint32(header[14:], 40)
+	binary.LittleEndian.PutUint32(header[18:], uint32(width))
+	binary.LittleEndian.PutUint32(header[22:], uint32(height))
+	binary.LittleEndian.PutUint16(header[26:], 1)
+	binary.LittleEndian.PutUint16(header[28:], 24)
+	binary.LittleEndian.PutUint32(header[34:], uint32(rowSize*height))
+
+	writer := bufio.NewWriter(out)
+	writer.Write(header)
+
+	shots := h.blockShares(h.Shots, cellsPerPixel)
+	hits := h.blockShares(h.Hits, cellsPerPixel)
+	shotColor := [3]byte{255, 96, 32}
+	hitColor := [3]byte{32, 32, 255}
+	gapColor := [3]byte{255, 255, 255}
+	row := make([]byte, rowSize)
+	// BMP rows go from the bottom of the picture to the top.
+	for y := height - 1; y >= 0; y-- {
+		for x := 0; x < width; x++ {
+			var color [3]byte
+			switch {
+			case x < panelWidth:
+				block := Pair{X: x / pixelsPerCell, Y: y / pixelsPerCell}
+				color = blendColor(shots[block], shotColor)
+			case x < panelWidth+gap:
+				color = gapColor
+			default:
+				block := Pair{X: (x - panelWidth - gap) / pixelsPerCell, Y: y / pixelsPerCell}
+				color = blendColor(hits[block], hitColor)
+			}
+			// BMP stores the channels as blue, green, red.
+			row[x*3], row[x*3+1], row[x*3+2] = color[2], color[1], color[0]
+		}
+		writer.Write(row)
+	}
+	return writer.Flush()
+}
+
+// HandleStatsCommand runs "stats", "stats csv <file>" and
+// "stats bmp <file> [scale]".
+func (g *Game) HandleStatsCommand(args []string) string {
+	if len(args) == 0 {
+		return strings.Join([]string{
+			"ours: " + g.OurStats().String(),
+			"theirs: " + g.TheirStats().String(),
+		}, "\n")
+	}
+	if len(args) < 2 || (args[0] != "csv" && args[0] != "bmp") || g.width <= 0 || g.height <= 0 {
+		return "failed"
+	}
+	heatmap := g.ShotHeatmap()
+	if args[0] == "bmp" && len(args) > 2 {
+		scale, err := strconv.Atoi(args[2])
+		if err != nil || scale <= 0 {
+			return "failed"
+		}
+		heatmap.Scale = scale
+	}
+
+	file, err := os.Create(args[1])
+	if err != nil {
+		return "failed"
+	}
+	defer file.Close()
+	if args[0] == "csv" {
+		err = heatmap.WriteCSV(file)
+	} else {
+		err = heatmap.WriteBMP(file)
+	}
+	if err != nil {
+		return "failed"
+	}
+	return "ok"
+}
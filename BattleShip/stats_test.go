@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newStatsGame(t *testing.T) *Game {
+	g := NewGame()
+	g.isGameCreated = true
+	g.width = 4
+	g.height = 2
+	g.shipCounts[2] = 1
+	g.PlaceShip(2, 0, 0, false)
+	g.allShipsPlaced = true
+	runCommands(t, g, "start")
+	return g
+}
+
+func TestStats(t *testing.T) {
+	g := newStatsGame(t)
+	for _, shot := range []string{"3 1", "2 1", "1 1", "0 0", "2 0", "1 0"} {
+		g.HandleCommand("shot " + shot)
+	}
+	g.target.Mark(Pair{X: 0, Y: 1}, MISS)
+	g.target.Mark(Pair{X: 1, Y: 1}, HIT)
+	g.target.Mark(Pair{X: 2, Y: 1}, KILL)
+
+	ours := g.OurStats()
+	if ours.Shots != 3 || ours.Hits != 2 || ours.Kills != 1 || ours.LongestMissStreak != 1 {
+		t.Errorf("Unexpected stats of our shots: %+v", ours)
+	}
+	theirs := g.TheirStats()
+	if theirs.Shots != 6 || theirs.Hits != 2 || theirs.Kills != 1 || theirs.LongestMissStreak != 3 {
+		t.Errorf("Unexpected stats of their shots: %+v", theirs)
+	}
+
+	expected := "ours: 3 shots, 2 hits (66.7%), 1 kills, 3.0 shots per kill, longest miss streak 1\n" +
+		"theirs: 6 shots, 2 hits (33.3%), 1 kills, 6.0 shots per kill, longest miss streak 3"
+	if reply := g.HandleCommand("stats"); reply != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, reply)
+	}
+}
+
+func TestHeatmapExport(t *testing.T) {
+	g := newStatsGame(t)
+	g.HandleCommand("shot 1 0")
+	g.HandleCommand("shot 3 1")
+
+	var csv bytes.Buffer
+	if err := g.ShotHeatmap().WriteCSV(&csv); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "x,y,shots,hits\n1,0,1,1\n3,1,1,0\n"; csv.String() != expected {
+		t.Errorf("Expected CSV:\n%s\ngot:\n%s", expected, csv.String())
+	}
+
+	var image bytes.Buffer
+	if err := g.ShotHeatmap().WriteBMP(&image); err != nil {
+		t.Fatal(err)
+	}
+	data := image.Bytes()
+	if !strings.HasPrefix(image.String(), "BM") || int(binary.LittleEndian.Uint32(data[2:])) != len(data) {
+		t.Fatalf("Expected a BMP file of %d bytes", len(data))
+	}
+	width := int(binary.LittleEndian.Uint32(data[18:]))
+	height := int(binary.LittleEndian.Uint32(data[22:]))
+	if width != 2*512+4 || height != 256 {
+		t.Errorf("Expected a 1028x256 image, got %dx%d", width, height)
+	}
+
+	// Rows are stored bottom up, so the last row in the file is the top of
+	// the picture. Its first pixel shows cell 0 0 and pixel 128 cell 1 0;
+	// every pixel is stored as blue, green, red.
+	rowSize := (width*3 + 3) / 4 * 4
+	top := data[len(data)-rowSize:]
+	pixel := func(x int) [3]byte { return [3]byte{top[x*3], top[x*3+1], top[x*3+2]} }
+	if empty := pixel(0); empty != [3]byte{24, 24, 24} {
+		t.Errorf("Expected cell 0 0 to be empty, got %v", empty)
+	}
+	if shot := pixel(128); shot != [3]byte{32, 96, 255} {
+		t.Errorf("Expected cell 1 0 to be drawn red in BGR order, got %v", shot)
+	}
+	if hit := pixel(512 + 4 + 128); hit != [3]byte{255, 32, 32} {
+		t.Errorf("Expected the hit on cell 1 0 to be drawn blue in BGR order, got %v", hit)
+	}
+
+	// The colors follow the scale, not the busiest cell.
+	heatmap := NewHeatmap(4, 4)
+	heatmap.Scale = 4
+	heatmap.Shots[Pair{X: 0, Y: 0}] = 2
+	image.Reset()
+	if err := heatmap.WriteBMP(&image); err != nil {
+		t.Fatal(err)
+	}
+	data = image.Bytes()
+	if half := data[len(data)-rowSize:][:3]; half[0] != 28 || half[1] != 60 || half[2] != 139 {
+		t.Errorf("Expected a half-shaded cell for 2 shots out of 4, got %v", half)
+	}
+	if reply := g.HandleCommand("stats bmp " + filepath.Join(t.TempDir(), "map.bmp") + " 0"); reply != "failed" {
+		t.Errorf("Expected a zero scale to be rejected, got %s", reply)
+	}
+}
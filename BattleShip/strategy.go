@@ -0,0 +1,60 @@
+package main
+
+import (
+	"sort"
+)
+
+// Strategy picks the cells we fire at. NextShot returns Pair{-1, -1} when
+// there is nothing left to shoot, OnResult is called once the opponent has
+// answered a shot and Reset forgets everything learnt about the opponent.
+type Strategy interface {
+	NextShot(g *Game) Pair
+	OnResult(g *Game, shot Pair, result ShotResult)
+	Reset()
+}
+
+// StrategyState is implemented by strategies that keep state between shots.
+// SaveState returns save file lines, LoadState reports whether it consumed
+// a line read back from a save file.
+type StrategyState interface {
+	SaveState() []string
+	LoadState(fields []string) (bool, error)
+}
+
+var strategyRegistry = make(map[string]func() Strategy)
+
+// RegisterStrategy makes a strategy available to "set strategy". Strategies
+// register themselves from init in their own files.
+func RegisterStrategy(name string, factory func() Strategy) {
+	if _, ok := strategyRegistry[name]; ok {
+		panic("strategy " + name + " is registered twice")
+	}
+	strategyRegistry[name] = factory
+}
+
+func NewStrategy(name string) (Strategy, bool) {
+	factory, ok := strategyRegistry[name]
+	if !ok {
+		return nil, false
+	}
+	return factory(), true
+}
+
+func StrategyNames() []string {
+	names := make([]string, 0, len(strategyRegistry))
+	for name := range strategyRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (g *Game) SetStrategy(name string) bool {
+	strategy, ok := NewStrategy(name)
+	if !ok {
+		return false
+	}
+	g.strategyName = name
+	g.strategy = strategy
+	return true
+}
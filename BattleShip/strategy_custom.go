@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+)
+
+// CustomStrategy fires at random until it hits something, then finishes
+// the ship off by shooting around the hits.
+type CustomStrategy struct {
+	hits []Pair
+}
+
+func init() {
+	RegisterStrategy("custom", func() Strategy { return &CustomStrategy{} })
+}
+
+func (s *CustomStrategy) NextShot(g *Game) Pair {
+	if len(s.hits) == 2 {
+		x1, y1 := s.hits[0].X, s.hits[0].Y
+		x2, y2 := s.hits[1].X, s.hits[1].Y
+		ends := make([]Pair, 0)
+		if x1 == x2 {
+			ends = append(ends, Pair{X: x1, Y: max(y1, y2) + 1}, Pair{X: x1, Y: min(y1, y2) - 1})
+		} else if y1 == y2 {
+			ends = append(ends, Pair{X: max(x1, x2) + 1, Y: y1}, Pair{X: min(x1, x2) - 1, Y: y1})
+		}
+		for _, end := range ends {
+			if g.IsValidCoordinate(end.X, end.Y) && g.target.IsUnknown(end.X, end.Y) {
+				return end
+			}
+		}
+	}
+	for _, hit := range s.hits {
+		nextShot := nextShotFromHit(g, hit.X, hit.Y)
+		if nextShot.X != -1 && nextShot.Y != -1 {
+			return nextShot
+		}
+	}
+	if g.target.ShotCount()+g.target.ReservedCount() >= g.width*g.height {
+		return Pair{X: -1, Y: -1}
+	}
+	for {
+		x := g.rng.Intn(g.width)
+		y := g.rng.Intn(g.height)
+		if g.target.IsUnknown(x, y) {
+			return Pair{X: x, Y: y}
+		}
+	}
+}
+
+func nextShotFromHit(g *Game, x, y int) Pair {
+	neighbours := []Pair{
+		{X: x + 1, Y: y},
+		{X: x - 1, Y: y},
+		{X: x, Y: y + 1},
+		{X: x, Y: y - 1},
+	}
+	for _, nextShot := range neighbours {
+		if g.IsValidCoordinate(nextShot.X, nextShot.Y) && g.target.IsUnknown(nextShot.X, nextShot.Y) {
+			return nextShot
+		}
+	}
+	return Pair{X: -1, Y: -1}
+}
+
+func (s *CustomStrategy) OnResult(g *Game, shot Pair, result ShotResult) {
+	switch result {
+	case HIT:
+		s.hits = append(s.hits, shot)
+	case KILL:
+		s.hits = nil
+	}
+}
+
+func (s *CustomStrategy) Reset() {
+	s.hits = nil
+}
+
+func (s *CustomStrategy) SaveState() []string {
+	lines := make([]string, len(s.hits))
+	for i, hit := range s.hits {
+		lines[i] = fmt.Sprintf("chase %d %d", hit.X, hit.Y)
+	}
+	return lines
+}
+
+func (s *CustomStrategy) LoadState(fields []string) (bool, error) {
+	if fields[0] != "chase" {
+		return false, nil
+	}
+	values, err := atoiAll(fields[1:])
+	if err != nil || len(values) != 2 {
+		return true, fmt.Errorf("Invalid chase")
+	}
+	s.hits = append(s.hits, Pair{X: values[0], Y: values[1]})
+	return true, nil
+}
@@ -0,0 +1,184 @@
+package main
+
+const (
+	densityScanLimit   = 1 << 16
+	densitySampleSize  = 4096
+	densityHitBonus    = 50
+	densityUnknownCell = -1
+)
+
+// DensityStrategy fires where the remaining ships are most likely to be.
+// Everything it needs is on the tracking board, so it keeps no state.
+type DensityStrategy struct{}
+
+func init() {
+	RegisterStrategy("density", func() Strategy { return DensityStrategy{} })
+}
+
+func (DensityStrategy) NextShot(g *Game) Pair {
+	return g.GetDensityShot()
+}
+
+func (DensityStrategy) OnResult(g *Game, shot Pair, result ShotResult) {}
+
+func (DensityStrategy) Reset() {}
+
+// RemainingFleet lists the opponent ships that are still afloat. Sunk
+// ships are matched to the fleet by their shape.
+func (g *Game) RemainingFleet() []FleetEntry {
+	sunk := make(map[string]int)
+	for signature, count := range g.target.sunkShapes {
+		sunk[signature] = count
+	}
+	remaining := make([]FleetEntry, 0)
+	for _, entry := range g.Fleet() {
+		signature := entry.Template.Signature()
+		matched := min(entry.Count, sunk[signature])
+		sunk[signature] -= matched
+		if entry.Count > matched {
+			remaining = append(remaining, FleetEntry{Template: entry.Template, Count: entry.Count - matched})
+		}
+	}
+	return remaining
+}
+
+func (g *Game) targetState(x, y int) int {
+	result, ok := g.target.Result(Pair{X: x, Y: y})
+	if !ok {
+		return densityUnknownCell
+	}
+	return int(result)
+}
+
+// placementWeight returns how strongly a ship lying in the given position
+// supports the cells it covers, or 0 when the placement contradicts the
+// shots taken so far.
+func (g *Game) placementWeight(ship Ship) int {
+	covered := 0
+	for i := 0; i < ship.Size; i++ {
+		cell := ship.Cell(i)
+		if !g.IsValidCoordinate(cell.X, cell.Y) {
+			return 0
+		}
+		switch g.targetState(cell.X, cell.Y) {
+		case int(MISS), int(KILL):
+			return 0
+		case int(HIT):
+			covered++
+		}
+		if g.rules.NoTouch && g.touchesSunkShip(cell.X, cell.Y) {
+			return 0
+		}
+	}
+	return 1 + densityHitBonus*covered
+}
+
+func (g *Game) touchesSunkShip(x, y int) bool {
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			if g.targetState(x+dx, y+dy) == int(KILL) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (g *Game) CellDensity(x, y int, remaining []FleetEntry) int {
+	score := 0
+	for _, entry := range remaining {
+		for _, ship := range entry.Template.Orientations() {
+			for i := 0; i < ship.Size; i++ {
+				offset := ship.Cell(i)
+				placed := ship
+				placed.X, placed.Y = x-offset.X, y-offset.Y
+				score += entry.Count * g.placementWeight(placed)
+			}
+		}
+	}
+	return score
+}
+
+func (g *Game) densityCandidates(remaining []FleetEntry) []Pair {
+	maxSize := 0
+	shaped := false
+	for _, entry := range remaining {
+		maxSize = max(maxSize, entry.Template.Size)
+		shaped = shaped || entry.Template.Shape != nil
+	}
+
+	candidates := make([]Pair, 0)
+	seen := make(map[Pair]bool)
+	add := func(x, y int) {
+		cell := Pair{X: x, Y: y}
+		if seen[cell] || !g.IsValidCoordinate(x, y) || g.targetState(x, y) != densityUnknownCell || g.target.IsReserved(cell) {
+			return
+		}
+		seen[cell] = true
+		candidates = append(candidates, cell)
+	}
+
+	// Straight ships continue a hit along its row or column, shapes may
+	// bend anywhere within their size.
+	for _, cell := range g.target.Hits() {
+		for d := 1; d < maxSize; d++ {
+			add(cell.X+d, cell.Y)
+			add(cell.X-d, cell.Y)
+			add(cell.X, cell.Y+d)
+			add(cell.X, cell.Y-d)
+		}
+		if !shaped {
+			continue
+		}
+		for dy := 1 - maxSize; dy < maxSize; dy++ {
+			for dx := 1 - maxSize; dx < maxSize; dx++ {
+				add(cell.X+dx, cell.Y+dy)
+			}
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates
+	}
+
+	if int64(g.width)*int64(g.height) <= densityScanLimit {
+		for y := 0; y < g.height; y++ {
+			for x := 0; x < g.width; x++ {
+				add(x, y)
+			}
+		}
+		return candidates
+	}
+
+	for i := 0; i < densitySampleSize; i++ {
+		add(g.rng.Intn(g.width), g.rng.Intn(g.height))
+	}
+	return candidates
+}
+
+// GetDensityShot fires at the unknown cell covered by the largest number of
+// ship placements that are still possible. Boards larger than
+// densityScanLimit cells are scored on a random sample while hunting.
+func (g *Game) GetDensityShot() Pair {
+	remaining := g.RemainingFleet()
+	if len(remaining) == 0 {
+		return Pair{X: -1, Y: -1}
+	}
+
+	best := Pair{X: -1, Y: -1}
+	bestScore := -1
+	ties := 0
+	for _, cell := range g.densityCandidates(remaining) {
+		score := g.CellDensity(cell.X, cell.Y, remaining)
+		if score > bestScore {
+			best = cell
+			bestScore = score
+			ties = 1
+		} else if score == bestScore {
+			ties++
+			if g.rng.Intn(ties) == 0 {
+				best = cell
+			}
+		}
+	}
+	return best
+}
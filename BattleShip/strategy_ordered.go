@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+)
+
+// OrderedStrategy fires at every cell row by row. The cursor only moves
+// past cells whose result is known, so a shot that was refused or never
+// answered is fired again.
+type OrderedStrategy struct {
+	cursor Pair
+}
+
+func init() {
+	RegisterStrategy("ordered", func() Strategy { return &OrderedStrategy{} })
+}
+
+func (s *OrderedStrategy) NextShot(g *Game) Pair {
+	for s.cursor.Y < g.height {
+		if _, known := g.target.Result(s.cursor); !known {
+			break
+		}
+		s.cursor = nextInRow(s.cursor, g.width)
+	}
+	for cell := s.cursor; cell.Y < g.height; cell = nextInRow(cell, g.width) {
+		if g.target.IsUnknown(cell.X, cell.Y) {
+			return cell
+		}
+	}
+	return Pair{X: -1, Y: -1}
+}
+
+func nextInRow(cell Pair, width int) Pair {
+	cell.X++
+	if cell.X >= width {
+		cell.X = 0
+		cell.Y++
+	}
+	return cell
+}
+
+func (s *OrderedStrategy) OnResult(g *Game, shot Pair, result ShotResult) {}
+
+func (s *OrderedStrategy) Reset() {
+	s.cursor = Pair{}
+}
+
+func (s *OrderedStrategy) SaveState() []string {
+	return []string{fmt.Sprintf("cursor %d %d", s.cursor.X, s.cursor.Y)}
+}
+
+func (s *OrderedStrategy) LoadState(fields []string) (bool, error) {
+	if fields[0] != "cursor" {
+		return false, nil
+	}
+	values, err := atoiAll(fields[1:])
+	if err != nil || len(values) != 2 {
+		return true, fmt.Errorf("Invalid cursor")
+	}
+	s.cursor = Pair{X: values[0], Y: values[1]}
+	return true, nil
+}
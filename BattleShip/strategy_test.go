@@ -0,0 +1,113 @@
+package main
+
+import (
+	"testing"
+)
+
+func playSolitaire(t testing.TB, strategy string, seed int64) int {
+	defender := newClassicFleet(t, 10, 10)
+	defender.SetSeed(seed)
+	defender.rules = ClassicRules()
+	if err := defender.RandomizeShipPlacement(); err != nil {
+		t.Fatalf("placement: %v", err)
+	}
+	defender.gameStarted = true
+
+	attacker := newClassicFleet(t, 10, 10)
+	attacker.SetSeed(seed)
+	attacker.rules = ClassicRules()
+	if reply := attacker.HandleCommand("set strategy " + strategy); reply != "ok" {
+		t.Fatalf("set strategy %s: got %s", strategy, reply)
+	}
+
+	shots := 0
+	for !defender.IsLoser() {
+		shot := attacker.GetNextShot()
+		if shot.X < 0 {
+			t.Fatalf("%s ran out of shots after %d", strategy, shots)
+		}
+		shots++
+		result := defender.HandleShotCommand(shot.X, shot.Y)
+		attacker.HandleCommand("set result " + result)
+	}
+	return shots
+}
+
+func TestDensityStrategySinksFleet(t *testing.T) {
+	const games = 30
+	average := make(map[string]float64)
+	for _, strategy := range []string{"ordered", "custom", "density"} {
+		for seed := int64(0); seed < games; seed++ {
+			average[strategy] += float64(playSolitaire(t, strategy, seed)) / games
+		}
+	}
+	t.Logf("average shots: %v", average)
+	if average["density"] > 70 {
+		t.Errorf("Expected density to average at most 70 shots, got %.1f", average["density"])
+	}
+	if average["density"] >= average["ordered"] || average["density"] >= average["custom"] {
+		t.Errorf("Expected density to beat ordered and custom, got %v", average)
+	}
+}
+
+func TestDensityPrefersCellsNextToHits(t *testing.T) {
+	g := newClassicFleet(t, 10, 10)
+	g.target.Mark(Pair{X: 5, Y: 5}, HIT)
+	shot := g.GetDensityShot()
+	dx, dy := shot.X-5, shot.Y-5
+	if dx*dx+dy*dy != 1 {
+		t.Errorf("Expected a shot adjacent to (5, 5), got %v", shot)
+	}
+}
+
+func BenchmarkStrategies(b *testing.B) {
+	for _, strategy := range []string{"ordered", "custom", "density"} {
+		b.Run(strategy, func(b *testing.B) {
+			total := 0
+			for i := 0; i < b.N; i++ {
+				total += playSolitaire(b, strategy, int64(i))
+			}
+			b.ReportMetric(float64(total)/float64(b.N), "shots/game")
+		})
+	}
+}
+
+type diagonalStrategy struct {
+	next int
+}
+
+func (s *diagonalStrategy) NextShot(g *Game) Pair {
+	if s.next >= min(g.width, g.height) {
+		return Pair{X: -1, Y: -1}
+	}
+	s.next++
+	return Pair{X: s.next - 1, Y: s.next - 1}
+}
+
+func (s *diagonalStrategy) OnResult(g *Game, shot Pair, result ShotResult) {}
+
+func (s *diagonalStrategy) Reset() {
+	s.next = 0
+}
+
+func TestStrategyRegistry(t *testing.T) {
+	RegisterStrategy("test-diagonal", func() Strategy { return &diagonalStrategy{} })
+	defer delete(strategyRegistry, "test-diagonal")
+
+	g := newClassicFleet(t, 3, 3)
+	if reply := g.HandleCommand("set strategy test-diagonal"); reply != "ok" {
+		t.Fatalf("set strategy: got %s", reply)
+	}
+	for i := 0; i < 3; i++ {
+		if shot := g.GetNextShot(); shot != (Pair{X: i, Y: i}) {
+			t.Errorf("Expected shot at (%d, %d), got %v", i, i, shot)
+		}
+		g.HandleCommand("set result miss")
+	}
+	if shot := g.GetNextShot(); shot.X != -1 {
+		t.Errorf("Expected the strategy to run out of shots, got %v", shot)
+	}
+	if g.HandleCommand("set strategy missing") != "failed" {
+		t.Errorf("Expected an unknown strategy to be rejected")
+	}
+}
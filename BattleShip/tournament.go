@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type TournamentConfig struct {
+	Games      int
+	Strategies []string
+	Width      int
+	Height     int
+	Counts     map[int]int
+	Shapes     map[string]int
+	Rules      Rules
+	Seed       int64
+	Workers    int
+}
+
+type PairingResult struct {
+	First, Second string
+	Games         int
+	FirstWins     int
+	FirstShots    []int
+	SecondShots   []int
+}
+
+type countingPlayer struct {
+	player Player
+	shots  int
+}
+
+// Send counts every cell of the volley the player fires.
+func (p *countingPlayer) Send(command string) (string, error) {
+	reply, err := p.player.Send(command)
+	if command == "shot" && err == nil && reply != "failed" {
+		p.shots += max(1, len(strings.Fields(reply))/2)
+	}
+	return reply, err
+}
+
+func (c TournamentConfig) newPlayer(strategy string, seed int64) (*Game, error) {
+	g := NewGame()
+	g.SetSeed(seed)
+	g.isGameCreated = true
+	g.width = c.Width
+	g.height = c.Height
+	g.rules = c.Rules
+	for size, count := range c.Counts {
+		g.shipCounts[size] = count
+	}
+	for name, count := range c.Shapes {
+		g.shapeCounts[name] = count
+	}
+	if reply := g.HandleCommand("set strategy " + strategy); reply != "ok" {
+		return nil, fmt.Errorf("Unknown strategy %s", strategy)
+	}
+	if reply := g.HandleCommand("start"); reply != "ok" {
+		return nil, fmt.Errorf("Failed to start game for %s", strategy)
+	}
+	return g, nil
+}
+
+// playTournamentGame plays one game of a pairing. The side that moves first
+// alternates between games so neither strategy gets the first shot for free.
+func (c TournamentConfig) playTournamentGame(result *PairingResult, index int, seed int64) (bool, int, int, error) {
+	first, err := c.newPlayer(result.First, seed)
+	if err != nil {
+		return false, 0, 0, err
+	}
+	second, err := c.newPlayer(result.Second, seed+1)
+	if err != nil {
+		return false, 0, 0, err
+	}
+
+	firstPlayer := &countingPlayer{player: &LocalPlayer{game: first}}
+	secondPlayer := &countingPlayer{player: &LocalPlayer{game: second}}
+	if index%2 == 0 {
+		winner, err := PlayMatch(firstPlayer, secondPlayer, c.Rules)
+		return winner == MASTER, firstPlayer.shots, secondPlayer.shots, err
+	}
+	winner, err := PlayMatch(secondPlayer, firstPlayer, c.Rules)
+	return winner == SLAVE, firstPlayer.shots, secondPlayer.shots, err
+}
+
+func RunTournament(config TournamentConfig) ([]*PairingResult, error) {
+	if config.Workers <= 0 {
+		config.Workers = runtime.NumCPU()
+	}
+
+	results := make([]*PairingResult, 0)
+	for i := 0; i < len(config.Strategies); i++ {
+		for j := i + 1; j < len(config.Strategies); j++ {
+			results = append(results, &PairingResult{
+				First:       config.Strategies[i],
+				Second:      config.Strategies[j],
+				Games:       config.Games,
+				FirstShots:  make([]int, config.Games),
+				SecondShots: make([]int, config.Games),
+			})
+		}
+	}
+
+	type job struct {
+		pairing int
+		index   int
+	}
+	jobs := make(chan job)
+	var mutex sync.Mutex
+	var firstErr error
+	var wg sync.WaitGroup
+	for w := 0; w < config.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				result := results[j.pairing]
+				seed := config.Seed + int64(j.pairing*config.Games+j.index)*2
+				won, firstShots, secondShots, err := config.playTournamentGame(result, j.index, seed)
+
+				mutex.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				if won {
+					result.FirstWins++
+				}
+				mutex.Unlock()
+				result.FirstShots[j.index] = firstShots
+				result.SecondShots[j.index] = secondShots
+			}
+		}()
+	}
+
+	for pairing := range results {
+		for index := 0; index < config.Games; index++ {
+			jobs <- job{pairing: pairing, index: index}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, firstErr
+}
+
+// WilsonInterval returns the 95% Wilson score interval of a win rate.
+func WilsonInterval(wins, games int) (float64, float64) {
+	if games == 0 {
+		return 0, 1
+	}
+	const z = 1.96
+	n := float64(games)
+	p := float64(wins) / n
+	denominator := 1 + z*z/n
+	center := (p + z*z/(2*n)) / denominator
+	margin := z * math.Sqrt(p*(1-p)/n+z*z/(4*n*n)) / denominator
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// MeanInterval returns the mean and the half-width of its 95% confidence
+// interval under the normal approximation.
+func MeanInterval(values []int) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	n := float64(len(values))
+	sum := 0.0
+	for _, value := range values {
+		sum += float64(value)
+	}
+	mean := sum / n
+	if len(values) < 2 {
+		return mean, 0
+	}
+	variance := 0.0
+	for _, value := range values {
+		variance += (float64(value) - mean) * (float64(value) - mean)
+	}
+	variance /= n - 1
+	return mean, 1.96 * math.Sqrt(variance/n)
+}
+
+func (r *PairingResult) String() string {
+	low, high := WilsonInterval(r.FirstWins, r.Games)
+	firstMean, firstMargin := MeanInterval(r.FirstShots)
+	secondMean, secondMargin := MeanInterval(r.SecondShots)
+	return fmt.Sprintf("%s vs %s: %d games, %s wins %.1f%% [%.1f%%, %.1f%%], shots %s %.1f ± %.1f, %s %.1f ± %.1f",
+		r.First, r.Second, r.Games,
+		r.First, 100*float64(r.FirstWins)/float64(r.Games), 100*low, 100*high,
+		r.First, firstMean, firstMargin, r.Second, secondMean, secondMargin)
+}
+
+// HandleTournamentCommand runs "tournament <games> <strategy> <strategy>..."
+// on the board size, fleet and rules configured on this game.
+func (g *Game) HandleTournamentCommand(args []string) string {
+	if len(args) < 3 || g.width <= 0 || g.height <= 0 {
+		return "failed"
+	}
+	games, err := strconv.Atoi(args[0])
+	if err != nil || games <= 0 {
+		return "failed"
+	}
+
+	config := TournamentConfig{
+		Games:      games,
+		Strategies: args[1:],
+		Width:      g.width,
+		Height:     g.height,
+		Counts:     g.shipCounts,
+		Shapes:     g.shapeCounts,
+		Rules:      g.rules,
+		// The tournament is not journaled, so it must not draw from the
+		// game's own random source.
+		Seed: g.seed,
+	}
+	results, err := RunTournament(config)
+	if err != nil {
+		return "failed"
+	}
+
+	lines := make([]string, len(results))
+	for i, result := range results {
+		lines[i] = result.String()
+	}
+	return strings.Join(lines, "\n")
+}
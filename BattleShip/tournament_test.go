@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTournament(t *testing.T) {
+	config := TournamentConfig{
+		Games:      20,
+		Strategies: []string{"ordered", "custom", "density"},
+		Width:      10,
+		Height:     10,
+		Counts:     map[int]int{1: 4, 2: 3, 3: 2, 4: 1},
+		Rules:      ClassicRules(),
+		Seed:       5,
+	}
+	results, err := RunTournament(config)
+	if err != nil {
+		t.Fatalf("tournament: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 pairings, got %d", len(results))
+	}
+	for _, result := range results {
+		if result.FirstWins < 0 || result.FirstWins > result.Games {
+			t.Errorf("%s: invalid win count %d", result, result.FirstWins)
+		}
+		for i := range result.FirstShots {
+			if result.FirstShots[i] == 0 && result.SecondShots[i] == 0 {
+				t.Errorf("%s: game %d has no shots", result, i)
+			}
+		}
+	}
+
+	again, err := RunTournament(config)
+	if err != nil {
+		t.Fatalf("tournament: %v", err)
+	}
+	for i := range results {
+		if results[i].String() != again[i].String() {
+			t.Errorf("Expected the same seed to give the same results:\n%s\n%s", results[i], again[i])
+		}
+	}
+}
+
+func TestWilsonInterval(t *testing.T) {
+	low, high := WilsonInterval(50, 100)
+	if low < 0.40 || low > 0.41 || high < 0.59 || high > 0.60 {
+		t.Errorf("Expected about [0.40, 0.60], got [%f, %f]", low, high)
+	}
+	low, high = WilsonInterval(0, 10)
+	if low != 0 || high <= 0 || high >= 0.5 {
+		t.Errorf("Expected [0, <0.5] for no wins, got [%f, %f]", low, high)
+	}
+}
+
+func TestTournamentCommand(t *testing.T) {
+	g := newClassicFleet(t, 8, 8)
+	draws := g.source.draws
+	reply := g.HandleCommand("tournament 4 ordered density")
+	if !strings.HasPrefix(reply, "ordered vs density: 4 games") {
+		t.Errorf("Unexpected tournament reply: %s", reply)
+	}
+	if g.source.draws != draws {
+		t.Errorf("Expected the tournament to leave the game's random source alone")
+	}
+	if again := g.HandleCommand("tournament 4 ordered density"); again != reply {
+		t.Errorf("Expected the same seed to give the same tournament, got %s and %s", reply, again)
+	}
+	if g.HandleCommand("tournament 4 ordered unknown") != "failed" {
+		t.Errorf("Expected unknown strategy to fail")
+	}
+}
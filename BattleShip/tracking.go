@@ -0,0 +1,142 @@
+package main
+
+import (
+	"sort"
+)
+
+// TargetBoard is what we know about the opponent's field: the result of
+// every shot we fired in the order we fired it and the sizes and shapes of
+// the ships we have sunk. Cells planned for the volley being built are
+// reserved so that no strategy picks them twice.
+type TargetBoard struct {
+	cells      map[Pair]ShotResult
+	order      []Pair
+	sunk       map[int]int
+	sunkShapes map[string]int
+	reserved   map[Pair]bool
+}
+
+func NewTargetBoard() *TargetBoard {
+	return &TargetBoard{
+		cells:      make(map[Pair]ShotResult),
+		sunk:       make(map[int]int),
+		sunkShapes: make(map[string]int),
+		reserved:   make(map[Pair]bool),
+	}
+}
+
+func (b *TargetBoard) Result(cell Pair) (ShotResult, bool) {
+	result, ok := b.cells[cell]
+	return result, ok
+}
+
+func (b *TargetBoard) IsUnknown(x, y int) bool {
+	cell := Pair{X: x, Y: y}
+	_, ok := b.cells[cell]
+	return !ok && !b.reserved[cell]
+}
+
+func (b *TargetBoard) Reserve(cell Pair) {
+	b.reserved[cell] = true
+}
+
+func (b *TargetBoard) IsReserved(cell Pair) bool {
+	return b.reserved[cell]
+}
+
+func (b *TargetBoard) ReservedCount() int {
+	return len(b.reserved)
+}
+
+func (b *TargetBoard) ClearReserved() {
+	clear(b.reserved)
+}
+
+// Mark records the result of a shot. A kill turns the whole run of
+// connected hits into a sunk ship of that size.
+func (b *TargetBoard) Mark(cell Pair, result ShotResult) {
+	b.MarkRevealed(cell, result, 0)
+}
+
+// MarkRevealed is Mark for a kill whose size the opponent told us. When
+// ships may touch, the run of hits can be longer than the sunk ship; then
+// only the killing cell is marked and the other hits stay in play.
+func (b *TargetBoard) MarkRevealed(cell Pair, result ShotResult, size int) {
+	if _, ok := b.cells[cell]; !ok {
+		b.order = append(b.order, cell)
+	}
+	b.cells[cell] = result
+	if result != KILL {
+		return
+	}
+
+	ship := make([]Pair, 0)
+	stack := []Pair{cell}
+	visited := map[Pair]bool{cell: true}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		b.cells[current] = KILL
+		ship = append(ship, current)
+		neighbours := []Pair{
+			{X: current.X + 1, Y: current.Y},
+			{X: current.X - 1, Y: current.Y},
+			{X: current.X, Y: current.Y + 1},
+			{X: current.X, Y: current.Y - 1},
+		}
+		for _, next := range neighbours {
+			if result, ok := b.cells[next]; ok && result == HIT && !visited[next] {
+				visited[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	if size > 0 && size != len(ship) {
+		for _, current := range ship {
+			b.cells[current] = HIT
+		}
+		b.cells[cell] = KILL
+		b.sunk[size]++
+		b.sunkShapes[lineSignature(size)]++
+		return
+	}
+	b.sunk[len(ship)]++
+	b.sunkShapes[cellsSignature(ship)]++
+}
+
+func (b *TargetBoard) Hits() []Pair {
+	hits := make([]Pair, 0)
+	for cell, result := range b.cells {
+		if result == HIT {
+			hits = append(hits, cell)
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Y != hits[j].Y {
+			return hits[i].Y < hits[j].Y
+		}
+		return hits[i].X < hits[j].X
+	})
+	return hits
+}
+
+func (b *TargetBoard) SunkCount(size int) int {
+	return b.sunk[size]
+}
+
+func (b *TargetBoard) TotalSunk() int {
+	total := 0
+	for _, count := range b.sunk {
+		total += count
+	}
+	return total
+}
+
+// Shots returns the cells we fired at, oldest first.
+func (b *TargetBoard) Shots() []Pair {
+	return b.order
+}
+
+func (b *TargetBoard) ShotCount() int {
+	return len(b.cells)
+}
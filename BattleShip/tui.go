@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	keyUp = iota + 256
+	keyDown
+	keyRight
+	keyLeft
+	keyEnter
+	keyQuit
+)
+
+// tuiState keeps the bot shots still waiting for an answer. A salvo is
+// answered shot by shot and the results are sent together.
+type tuiState struct {
+	cursor  Pair
+	pending []Pair
+	answers []string
+	fog     bool
+	status  string
+}
+
+func stty(in *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = in
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+func enableRawMode(in *os.File) (func(), error) {
+	saved, err := stty(in, "-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty(in, "raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() {
+		stty(in, saved)
+	}, nil
+}
+
+func readKey(reader *bufio.Reader) (int, error) {
+	b, err := reader.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	switch b {
+	case 0x1b:
+		next, err := reader.ReadByte()
+		if err != nil || next != '[' {
+			return keyQuit, err
+		}
+		code, err := reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch code {
+		case 'A':
+			return keyUp, nil
+		case 'B':
+			return keyDown, nil
+		case 'C':
+			return keyRight, nil
+		case 'D':
+			return keyLeft, nil
+		}
+		return 0, nil
+	case '\r', '\n', ' ':
+		return keyEnter, nil
+	case 3, 4:
+		return keyQuit, nil
+	}
+	return int(b), nil
+}
+
+func readLine(reader *bufio.Reader, out io.Writer) (string, error) {
+	line := make([]byte, 0)
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		switch b {
+		case '\r', '\n':
+			return string(line), nil
+		case 0x1b, 3:
+			return "", nil
+		case 0x7f, 8:
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				fmt.Fprint(out, "\b \b")
+			}
+		default:
+			line = append(line, b)
+			fmt.Fprintf(out, "%c", b)
+		}
+	}
+}
+
+// awaited returns the bot shot that is answered next, or nil.
+func (state *tuiState) awaited() *Pair {
+	if len(state.answers) >= len(state.pending) {
+		return nil
+	}
+	return &state.pending[len(state.answers)]
+}
+
+func (g *Game) drawTUI(out io.Writer, state *tuiState) {
+	origin := Pair{X: state.cursor.X - showMaxColumns/2, Y: state.cursor.Y - showMaxRows/2}
+	board := g.RenderBoards(origin, RenderOptions{
+		Colored: true,
+		Fog:     state.fog,
+		Cursor:  &state.cursor,
+		Mark:    state.awaited(),
+	})
+
+	fmt.Fprint(out, "\x1b[H\x1b[2J")
+	fmt.Fprint(out, strings.ReplaceAll(board, "\n", "\r\n"))
+	fmt.Fprintf(out, "\r\n\r\ncursor %d %d", state.cursor.X, state.cursor.Y)
+	if shot := state.awaited(); shot != nil {
+		fmt.Fprintf(out, "   bot fired at %d %d: answer m(iss) / h(it) / k(ill)", shot.X, shot.Y)
+	}
+	fmt.Fprintf(out, "\r\n%s\r\n", state.status)
+	fmt.Fprint(out, "arrows move, enter fires at our fleet, b bot shot, f fog, : command, q quit\r\n")
+}
+
+// RunTUI draws both boards and lets the player fire with the cursor. Every
+// action is turned into an ordinary protocol command for HandleCommand.
+func (g *Game) RunTUI(in *os.File, out io.Writer) error {
+	if g.width <= 0 || g.height <= 0 {
+		return fmt.Errorf("Field size is not set")
+	}
+	restore, err := enableRawMode(in)
+	if err != nil {
+		return err
+	}
+	defer restore()
+	defer fmt.Fprint(out, "\r\n")
+
+	reader := bufio.NewReader(in)
+	state := &tuiState{}
+	results := map[int]string{'m': "miss", 'h': "hit", 'k': "kill"}
+	for {
+		g.drawTUI(out, state)
+		key, err := readKey(reader)
+		if err != nil {
+			return err
+		}
+
+		switch key {
+		case keyUp:
+			state.cursor.Y = max(0, state.cursor.Y-1)
+		case keyDown:
+			state.cursor.Y = min(g.height-1, state.cursor.Y+1)
+		case keyLeft:
+			state.cursor.X = max(0, state.cursor.X-1)
+		case keyRight:
+			state.cursor.X = min(g.width-1, state.cursor.X+1)
+		case keyEnter:
+			command := fmt.Sprintf("shot %d %d", state.cursor.X, state.cursor.Y)
+			state.status = command + ": " + g.HandleCommand(command)
+		case 'b':
+			response := g.Execute("shot")
+			if len(response.Shots) > 0 {
+				state.pending = response.Shots
+				state.answers = nil
+			}
+			state.status = "shot: " + response.String()
+		case 'm', 'h', 'k':
+			if state.awaited() == nil {
+				state.status = "no bot shot is waiting for a result"
+				continue
+			}
+			state.answers = append(state.answers, results[key])
+			if len(state.answers) < len(state.pending) {
+				state.status = "results so far: " + strings.Join(state.answers, " ")
+				continue
+			}
+			command := "set result " + strings.Join(state.answers, " ")
+			state.status = command + ": " + g.HandleCommand(command)
+			state.pending = nil
+			state.answers = nil
+		case 'f':
+			state.fog = !state.fog
+		case ':':
+			fmt.Fprint(out, ": ")
+			command, err := readLine(reader, out)
+			if err != nil {
+				return err
+			}
+			if command != "" {
+				response := g.Execute(command)
+				if response.Shutdown {
+					return nil
+				}
+				state.status = command + ": " + strings.ReplaceAll(response.String(), "\n", "\r\n")
+			}
+		case 'q', keyQuit:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package uint239
+
+import (
+	"math/bits"
+)
+
+// The functions in this file work on the logical values after removeShift.
+// Results are returned without a shift.
+
+// Cmp returns -1, 0 or 1 as lhs is less than, equal to or greater than rhs.
+func Cmp(lhs, rhs Uint239) int {
+	return compareRaw(removeShift(lhs), removeShift(rhs))
+}
+
+func Less(lhs, rhs Uint239) bool {
+	return Cmp(lhs, rhs) < 0
+}
+
+func And(lhs, rhs Uint239) Uint239 {
+	return combineRaw(removeShift(lhs), removeShift(rhs), func(a, b byte) byte { return a & b })
+}
+
+func Or(lhs, rhs Uint239) Uint239 {
+	return combineRaw(removeShift(lhs), removeShift(rhs), func(a, b byte) byte { return a | b })
+}
+
+func Xor(lhs, rhs Uint239) Uint239 {
+	return combineRaw(removeShift(lhs), removeShift(rhs), func(a, b byte) byte { return a ^ b })
+}
+
+// Not flips all 239 bits of the value.
+func Not(value Uint239) Uint239 {
+	return truncateRaw(combineRaw(removeShift(value), Uint239{}, func(a, _ byte) byte { return ^a }))
+}
+
+// Lsh shifts the bits of the value left by n, dropping the bits that move
+// past bit 238. It is unrelated to the circular shift kept in the service
+// bits.
+func Lsh(value Uint239, n uint) Uint239 {
+	source := removeShift(value)
+	result := Uint239{}
+
+	for bit := 238; bit >= int(n) && n < 239; bit-- {
+		setBitRaw(&result, bit, bitRaw(source, bit-int(n)))
+	}
+
+	return result
+}
+
+// Rsh shifts the bits of the value right by n.
+func Rsh(value Uint239, n uint) Uint239 {
+	source := removeShift(value)
+	result := Uint239{}
+
+	for bit := 0; n < 239 && bit+int(n) < 239; bit++ {
+		setBitRaw(&result, bit, bitRaw(source, bit+int(n)))
+	}
+
+	return result
+}
+
+// BitLen returns the number of bits needed to write the value, 0 for zero.
+func BitLen(value Uint239) int {
+	source := removeShift(value)
+
+	for i := 0; i < len(source.Data); i++ {
+
+		if limb := source.Data[i] & 0x7F; limb != 0 {
+			return (len(source.Data)-1-i)*7 + bits.Len8(limb)
+		}
+	}
+
+	return 0
+}
+
+// TrailingZeros returns the number of zero bits below the lowest set bit,
+// 239 for zero.
+func TrailingZeros(value Uint239) int {
+	source := removeShift(value)
+
+	for i := len(source.Data) - 1; i >= 0; i-- {
+
+		if limb := source.Data[i] & 0x7F; limb != 0 {
+			return (len(source.Data)-1-i)*7 + bits.TrailingZeros8(limb)
+		}
+	}
+
+	return 239
+}
+
+func PopCount(value Uint239) int {
+	source := removeShift(value)
+	count := 0
+
+	for i := 0; i < len(source.Data); i++ {
+		count += bits.OnesCount8(source.Data[i] & 0x7F)
+	}
+
+	return count
+}
+
+func combineRaw(lhs, rhs Uint239, operation func(a, b byte) byte) Uint239 {
+	result := Uint239{}
+
+	for i := 0; i < len(lhs.Data); i++ {
+		result.Data[i] = operation(lhs.Data[i]&0x7F, rhs.Data[i]&0x7F) & 0x7F
+	}
+
+	return result
+}
+
+func setBitRaw(value *Uint239, bit int, set byte) {
+	index := len(value.Data) - 1 - bit/7
+
+	value.Data[index] = value.Data[index]&^(1<<(bit%7)) | set<<(bit%7)
+}
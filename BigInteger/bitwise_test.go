@@ -0,0 +1,88 @@
+package uint239
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestBitwiseMatchesBig(t *testing.T) {
+	rng := rand.New(rand.NewSource(22))
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 239), big.NewInt(1))
+
+	for i := 0; i < 300; i++ {
+		lhs := randomBig(rng, 239)
+		rhs := randomBig(rng, 239)
+		n := uint(rng.Intn(260))
+
+		lhsValue, _ := FromBig(lhs, 0)
+		rhsValue, _ := FromBig(rhs, 0)
+
+		if Cmp(lhsValue, rhsValue) != lhs.Cmp(rhs) || Less(lhsValue, rhsValue) != (lhs.Cmp(rhs) < 0) {
+			t.Errorf("Cmp %v %v: got %d", lhs, rhs, Cmp(lhsValue, rhsValue))
+		}
+
+		checks := []struct {
+			name     string
+			result   Uint239
+			expected *big.Int
+		}{
+			{"and", And(lhsValue, rhsValue), new(big.Int).And(lhs, rhs)},
+			{"or", Or(lhsValue, rhsValue), new(big.Int).Or(lhs, rhs)},
+			{"xor", Xor(lhsValue, rhsValue), new(big.Int).Xor(lhs, rhs)},
+			{"not", Not(lhsValue), new(big.Int).Xor(lhs, mask)},
+			{"lsh", Lsh(lhsValue, n), new(big.Int).And(new(big.Int).Lsh(lhs, n), mask)},
+			{"rsh", Rsh(lhsValue, n), new(big.Int).Rsh(lhs, n)},
+		}
+		for _, check := range checks {
+			if check.result.ToBig().Cmp(check.expected) != 0 {
+				t.Errorf("%s %v %v (n=%d): expected %v, got %v", check.name, lhs, rhs, n, check.expected, check.result.ToBig())
+			}
+		}
+
+		if BitLen(lhsValue) != lhs.BitLen() {
+			t.Errorf("BitLen %v: expected %d, got %d", lhs, lhs.BitLen(), BitLen(lhsValue))
+		}
+
+		if lhs.Sign() != 0 && TrailingZeros(lhsValue) != int(lhs.TrailingZeroBits()) {
+			t.Errorf("TrailingZeros %v: expected %d, got %d", lhs, lhs.TrailingZeroBits(), TrailingZeros(lhsValue))
+		}
+
+		popCount := 0
+		for bit := 0; bit < lhs.BitLen(); bit++ {
+			popCount += int(lhs.Bit(bit))
+		}
+		if PopCount(lhsValue) != popCount {
+			t.Errorf("PopCount %v: expected %d, got %d", lhs, popCount, PopCount(lhsValue))
+		}
+	}
+
+	if BitLen(Uint239{}) != 0 || TrailingZeros(Uint239{}) != 239 || PopCount(Not(Uint239{})) != 239 {
+		t.Errorf("Unexpected bit counts of zero")
+	}
+}
+
+func TestCmpIgnoresShift(t *testing.T) {
+	testCases := []struct {
+		lhs, rhs Uint239
+		expected int
+	}{
+		{FromUint32(123, 5), FromUint32(123, 10), 0},
+		{FromUint32(122, 10), FromUint32(123, 5), -1},
+		{FromUint32(1000, 200), FromUint32(999, 0), 1},
+	}
+
+	for _, tc := range testCases {
+		if result := Cmp(tc.lhs, tc.rhs); result != tc.expected {
+			t.Errorf("Cmp %s %s: expected %d, got %d", tc.lhs, tc.rhs, tc.expected, result)
+		}
+	}
+
+	if Xor(FromUint32(6, 3), FromUint32(5, 0)).ToBig().Int64() != 3 {
+		t.Errorf("Expected 6 xor 5 = 3 whatever the shifts")
+	}
+
+	if BitLen(FromUint32(1, 238)) != 1 || TrailingZeros(FromUint32(8, 100)) != 3 {
+		t.Errorf("Expected bit counts of the logical value")
+	}
+}
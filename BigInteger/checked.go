@@ -0,0 +1,50 @@
+package uint239
+
+// AddChecked is Add that fails with ErrOverflow instead of wrapping.
+func AddChecked(lhs, rhs Uint239) (Uint239, error) {
+	lhsValue := removeShift(lhs)
+	rhsValue := removeShift(rhs)
+
+	if compareRaw(truncateRaw(addRaw(lhsValue, rhsValue)), lhsValue) < 0 {
+		return Uint239{}, ErrOverflow
+	}
+
+	return Add(lhs, rhs), nil
+}
+
+// SubChecked is Subtract that fails with ErrUnderflow when rhs is larger
+// than lhs.
+func SubChecked(lhs, rhs Uint239) (Uint239, error) {
+	if compareRaw(removeShift(lhs), removeShift(rhs)) < 0 {
+		return Uint239{}, ErrUnderflow
+	}
+
+	return Subtract(lhs, rhs), nil
+}
+
+// MulChecked is Multiply that fails with ErrOverflow instead of wrapping. A
+// product that wrapped no longer divides back to rhs.
+func MulChecked(lhs, rhs Uint239) (Uint239, error) {
+	lhsValue := removeShift(lhs)
+	rhsValue := removeShift(rhs)
+
+	if !isZeroRaw(lhsValue) {
+		product := multiplyRaw(lhsValue, rhsValue)
+
+		quotient, remainder := divModRaw(product, lhsValue)
+		if compareRaw(quotient, rhsValue) != 0 || !isZeroRaw(remainder) {
+			return Uint239{}, ErrOverflow
+		}
+	}
+
+	return Multiply(lhs, rhs), nil
+}
+
+// DivChecked is Divide that fails with ErrDivisionByZero.
+func DivChecked(lhs, rhs Uint239) (Uint239, error) {
+	if isZeroRaw(removeShift(rhs)) {
+		return Uint239{}, ErrDivisionByZero
+	}
+
+	return Divide(lhs, rhs), nil
+}
@@ -0,0 +1,86 @@
+package uint239
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestCheckedArithmeticMatchesBig(t *testing.T) {
+	rng := rand.New(rand.NewSource(21))
+	limit := new(big.Int).Lsh(big.NewInt(1), 239)
+
+	operations := []struct {
+		name    string
+		checked func(lhs, rhs Uint239) (Uint239, error)
+		exact   func(lhs, rhs *big.Int) *big.Int
+		failure error
+	}{
+		{"add", AddChecked, func(lhs, rhs *big.Int) *big.Int { return new(big.Int).Add(lhs, rhs) }, ErrOverflow},
+		{"sub", SubChecked, func(lhs, rhs *big.Int) *big.Int { return new(big.Int).Sub(lhs, rhs) }, ErrUnderflow},
+		{"mul", MulChecked, func(lhs, rhs *big.Int) *big.Int { return new(big.Int).Mul(lhs, rhs) }, ErrOverflow},
+	}
+
+	for _, operation := range operations {
+		for i := 0; i < 500; i++ {
+			lhs := randomBig(rng, 239)
+			rhs := randomBig(rng, 239)
+			if operation.name == "mul" {
+				rhs = randomBig(rng, 239-lhs.BitLen()+4)
+			}
+
+			lhsValue, _ := FromBig(lhs, 0)
+			rhsValue, _ := FromBig(rhs, 0)
+			expected := operation.exact(lhs, rhs)
+
+			result, err := operation.checked(lhsValue, rhsValue)
+			if expected.Sign() < 0 || expected.Cmp(limit) >= 0 {
+				if !errors.Is(err, operation.failure) {
+					t.Fatalf("%s %v %v: expected %v, got %v", operation.name, lhs, rhs, operation.failure, err)
+				}
+				continue
+			}
+
+			if err != nil || result.ToBig().Cmp(expected) != 0 {
+				t.Fatalf("%s %v %v: expected %v, got %v, %v", operation.name, lhs, rhs, expected, result.ToBig(), err)
+			}
+		}
+	}
+}
+
+func TestWrappingArithmeticIsModular(t *testing.T) {
+	maximum, _ := FromBig(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 239), big.NewInt(1)), 0)
+	one := FromUint32(1, 0)
+
+	if sum := Add(maximum, one); sum.ToBig().Sign() != 0 {
+		t.Errorf("Expected the maximum plus one to wrap to 0, got %v", sum.ToBig())
+	}
+
+	if difference := Subtract(Uint239{}, one); !Equal(difference, maximum) {
+		t.Errorf("Expected 0 minus one to wrap to the maximum, got %v", difference.ToBig())
+	}
+
+	if _, err := AddChecked(maximum, one); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Expected the maximum plus one to overflow, got %v", err)
+	}
+
+	if _, err := SubChecked(Uint239{}, one); !errors.Is(err, ErrUnderflow) {
+		t.Errorf("Expected 0 minus one to underflow, got %v", err)
+	}
+}
+
+func TestDivChecked(t *testing.T) {
+	if _, err := DivChecked(FromUint32(42, 0), Uint239{}); !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("Expected division by zero, got %v", err)
+	}
+
+	if quotient := Divide(FromUint32(42, 0), Uint239{}); quotient.ToBig().Sign() != 0 {
+		t.Errorf("Expected Divide by zero to keep returning 0, got %v", quotient.ToBig())
+	}
+
+	quotient, err := DivChecked(FromUint32(42, 0), FromUint32(5, 0))
+	if err != nil || quotient.ToBig().Int64() != 8 {
+		t.Errorf("Expected 42 / 5 = 8, got %v, %v", quotient.ToBig(), err)
+	}
+}
@@ -0,0 +1,121 @@
+package uint239
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// byteCount is the number of 8-bit bytes that hold 239 bits.
+const byteCount = 30
+
+// FromHex parses a hexadecimal number of up to 239 bits, with or without a
+// "0x" prefix.
+func FromHex(str string, shift uint32) (Uint239, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(str, "0x"), "0X")
+	if len(digits) == 0 {
+		return Uint239{}, fmt.Errorf("%w: %q", ErrSyntax, str)
+	}
+
+	value := Uint239{}
+
+	for _, ch := range digits {
+		var digit uint32
+
+		switch {
+		case ch >= '0' && ch <= '9':
+			digit = uint32(ch - '0')
+		case ch >= 'a' && ch <= 'f':
+			digit = uint32(ch-'a') + 10
+		case ch >= 'A' && ch <= 'F':
+			digit = uint32(ch-'A') + 10
+		default:
+			return Uint239{}, fmt.Errorf("%w: %q", ErrSyntax, str)
+		}
+
+		var overflow bool
+		value, overflow = multiplyAddSmallRaw(value, 16, digit)
+		if overflow {
+			return Uint239{}, fmt.Errorf("%w: %s", ErrOverflow, str)
+		}
+	}
+
+	return applyShift(value, shift%239), nil
+}
+
+// Hex formats the logical value in lowercase hexadecimal without a prefix.
+func (u Uint239) Hex() string {
+	hex := fmt.Sprintf("%x", u.Bytes())
+
+	hex = strings.TrimLeft(hex, "0")
+	if hex == "" {
+		return "0"
+	}
+
+	return hex
+}
+
+// FromBytes reads a big-endian number of up to 239 bits. Leading zero
+// bytes are allowed, so the slice may be longer than 30 bytes.
+func FromBytes(data []byte, shift uint32) (Uint239, error) {
+	value := Uint239{}
+
+	for _, b := range data {
+
+		var overflow bool
+		value, overflow = multiplyAddSmallRaw(value, 256, uint32(b))
+		if overflow {
+			return Uint239{}, fmt.Errorf("%w: %d bytes", ErrOverflow, len(data))
+		}
+	}
+
+	return applyShift(value, shift%239), nil
+}
+
+// FromBytesLE is FromBytes for little-endian input.
+func FromBytesLE(data []byte, shift uint32) (Uint239, error) {
+	reversed := make([]byte, len(data))
+
+	for i := range data {
+		reversed[len(data)-1-i] = data[i]
+	}
+
+	return FromBytes(reversed, shift)
+}
+
+// Bytes returns the logical value as 30 big-endian bytes.
+func (u Uint239) Bytes() []byte {
+	value := removeShift(u)
+	result := make([]byte, byteCount)
+
+	for bit := 0; bit < 239; bit++ {
+		result[byteCount-1-bit/8] |= bitRaw(value, bit) << (bit % 8)
+	}
+
+	return result
+}
+
+// BytesLE returns the logical value as 30 little-endian bytes.
+func (u Uint239) BytesLE() []byte {
+	result := u.Bytes()
+
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+
+	return result
+}
+
+// FromBig converts a non-negative big.Int of up to 239 bits.
+func FromBig(value *big.Int, shift uint32) (Uint239, error) {
+	if value.Sign() < 0 {
+		return Uint239{}, fmt.Errorf("%w: %v", ErrNegative, value)
+	}
+
+	return FromBytes(value.Bytes(), shift)
+}
+
+// ToBig returns the logical value as a big.Int.
+func (u Uint239) ToBig() *big.Int {
+	return new(big.Int).SetBytes(u.Bytes())
+}
@@ -0,0 +1,103 @@
+package uint239
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestHexRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(16))
+
+	for i := 0; i < 200; i++ {
+		value := randomBig(rng, 239)
+
+		result, err := FromHex(value.Text(16), 0)
+		if err != nil {
+			t.Fatalf("%x: unexpected error: %v", value, err)
+		}
+
+		if result.Hex() != value.Text(16) {
+			t.Errorf("%x: formatted as %s", value, result.Hex())
+		}
+	}
+
+	if value, err := FromHex("0xFF", 0); err != nil || value.Hex() != "ff" {
+		t.Errorf("Expected 0xFF to parse as ff, got %s, %v", value.Hex(), err)
+	}
+
+	for _, invalid := range []string{"", "0x", "12g"} {
+		if _, err := FromHex(invalid, 0); !errors.Is(err, ErrSyntax) {
+			t.Errorf("%q: expected a syntax error, got %v", invalid, err)
+		}
+	}
+
+	if _, err := FromHex("8"+string(bytes.Repeat([]byte("0"), 59)), 0); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Expected 2^239 to overflow, got %v", err)
+	}
+}
+
+func TestBytesRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(8))
+
+	for i := 0; i < 200; i++ {
+		value := randomBig(rng, 239)
+
+		expected := value.FillBytes(make([]byte, 30))
+
+		result, err := FromBytes(value.Bytes(), 0)
+		if err != nil {
+			t.Fatalf("%x: unexpected error: %v", value, err)
+		}
+
+		if !bytes.Equal(result.Bytes(), expected) {
+			t.Errorf("%x: big-endian bytes %x", value, result.Bytes())
+		}
+
+		littleEndian := result.BytesLE()
+		again, err := FromBytesLE(littleEndian, 0)
+		if err != nil || !Equal(again, result) {
+			t.Errorf("%x: little-endian round trip gave %x, %v", value, again.Bytes(), err)
+		}
+
+		if littleEndian[0] != expected[29] || littleEndian[29] != expected[0] {
+			t.Errorf("%x: little-endian bytes %x", value, littleEndian)
+		}
+	}
+
+	if _, err := FromBytes(bytes.Repeat([]byte{0xFF}, 30), 0); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Expected 240 bits to overflow, got %v", err)
+	}
+
+	padded := append(make([]byte, 8), 1, 2)
+	if value, err := FromBytes(padded, 0); err != nil || value.ToBig().Int64() != 258 {
+		t.Errorf("Expected leading zero bytes to be ignored, got %v, %v", value.ToBig(), err)
+	}
+}
+
+func TestBigRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+
+	for i := 0; i < 200; i++ {
+		value := randomBig(rng, 239)
+
+		result, err := FromBig(value, 0)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", value, err)
+		}
+
+		if result.ToBig().Cmp(value) != 0 || rawToBig(result).Cmp(value) != 0 {
+			t.Errorf("%v: converted to %v", value, result.ToBig())
+		}
+	}
+
+	if shifted, err := FromBig(big.NewInt(300), 5); err != nil || GetShift(shifted) != 5 {
+		t.Errorf("Expected the shift to be stored in the service bits, got %d, %v", GetShift(shifted), err)
+	}
+
+	if _, err := FromBig(big.NewInt(-1), 0); !errors.Is(err, ErrNegative) {
+		t.Errorf("Expected a negative value to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package uint239
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalText writes the logical value in decimal. The shift is not kept.
+func (u Uint239) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *Uint239) UnmarshalText(text []byte) error {
+	value, err := FromString(string(text), 0)
+	if err != nil {
+		return err
+	}
+
+	*u = value
+
+	return nil
+}
+
+// MarshalJSON writes the value as a decimal string, since JSON numbers
+// lose precision above 2^53.
+func (u Uint239) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + u.String() + `"`), nil
+}
+
+// UnmarshalJSON accepts a decimal string or a bare JSON number. Like the
+// standard types, it leaves the value unchanged on null.
+func (u *Uint239) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return fmt.Errorf("%w: %v", ErrSyntax, err)
+		}
+
+		return u.UnmarshalText([]byte(text))
+	}
+
+	return u.UnmarshalText(data)
+}
+
+// MarshalBinary writes the 35 bytes of the canonical form, shift included.
+func (u Uint239) MarshalBinary() ([]byte, error) {
+	value := Normalize(u)
+
+	return value.Data[:], nil
+}
+
+func (u *Uint239) UnmarshalBinary(data []byte) error {
+	value := Uint239{}
+
+	if len(data) != len(value.Data) {
+		return fmt.Errorf("%w: %d bytes instead of %d", ErrSyntax, len(data), len(value.Data))
+	}
+
+	copy(value.Data[:], data)
+
+	*u = Normalize(value)
+
+	return nil
+}
+
+// Format implements fmt.Formatter for the logical value with the verbs and
+// flags of big.Int: %d, %v and %s in decimal, %x and %X in hex, %b in
+// binary, %o in octal.
+func (u Uint239) Format(f fmt.State, verb rune) {
+	u.ToBig().Format(f, verb)
+}
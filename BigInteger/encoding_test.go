@@ -0,0 +1,167 @@
+package uint239
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func randomValues(seed int64) []Uint239 {
+	rng := rand.New(rand.NewSource(seed))
+	maximum, _ := FromBig(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 239), big.NewInt(1)), 17)
+
+	values := []Uint239{{}, FromUint32(1, 0), FromUint32(42, 200), maximum}
+	for i := 0; i < 100; i++ {
+		value, _ := FromBig(randomBig(rng, 239), uint32(rng.Intn(239)))
+		values = append(values, value)
+	}
+
+	return values
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	for _, value := range randomValues(1) {
+		text, err := value.MarshalText()
+		if err != nil {
+			t.Fatalf("%s: %v", value, err)
+		}
+
+		var decoded Uint239
+		if err := decoded.UnmarshalText(text); err != nil || !Equal(decoded, value) {
+			t.Errorf("%s: decoded as %s, %v", value, decoded, err)
+		}
+	}
+
+	var decoded Uint239
+	if err := decoded.UnmarshalText([]byte("12x")); !errors.Is(err, ErrSyntax) {
+		t.Errorf("Expected a syntax error, got %v", err)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	type record struct {
+		Value Uint239   `json:"value"`
+		List  []Uint239 `json:"list"`
+	}
+
+	values := randomValues(2)
+	data, err := json.Marshal(record{Value: values[3], List: values})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded record
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !Equal(decoded.Value, values[3]) || len(decoded.List) != len(values) {
+		t.Fatalf("Unexpected decoded record from %s", data)
+	}
+	for i := range values {
+		if !Equal(decoded.List[i], values[i]) {
+			t.Errorf("%s: decoded as %s", values[i], decoded.List[i])
+		}
+	}
+
+	if data, _ := json.Marshal(FromUint32(42, 9)); string(data) != `"42"` {
+		t.Errorf(`Expected "42", got %s`, data)
+	}
+
+	var number Uint239
+	if err := json.Unmarshal([]byte("12345"), &number); err != nil || number.String() != "12345" {
+		t.Errorf("Expected a bare number to decode, got %s, %v", number, err)
+	}
+
+	if err := json.Unmarshal([]byte("null"), &number); err != nil || number.String() != "12345" {
+		t.Errorf("Expected null to leave the value alone, got %s, %v", number, err)
+	}
+
+	if err := json.Unmarshal([]byte(`"\u0031\u0032"`), &number); err != nil || number.String() != "12" {
+		t.Errorf("Expected an escaped string to decode, got %s, %v", number, err)
+	}
+
+	for _, invalid := range []string{`"12`, `"1\2"`, `"-1"`, `""`} {
+		if err := number.UnmarshalJSON([]byte(invalid)); !errors.Is(err, ErrSyntax) {
+			t.Errorf("Expected %s to be a syntax error, got %v", invalid, err)
+		}
+	}
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	for _, value := range randomValues(3) {
+		data, err := value.MarshalBinary()
+		if err != nil || len(data) != 35 {
+			t.Fatalf("%s: %d bytes, %v", value, len(data), err)
+		}
+
+		var decoded Uint239
+		if err := decoded.UnmarshalBinary(data); err != nil || decoded != value {
+			t.Errorf("%s: decoded as %s with shift %d, %v", value, decoded, GetShift(decoded), err)
+		}
+	}
+
+	var decoded Uint239
+	if err := decoded.UnmarshalBinary(make([]byte, 30)); !errors.Is(err, ErrSyntax) {
+		t.Errorf("Expected a wrong length to fail, got %v", err)
+	}
+}
+
+func TestGobRoundTrip(t *testing.T) {
+	values := randomValues(4)
+
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(values); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded []Uint239
+	if err := gob.NewDecoder(&buffer).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range values {
+		if decoded[i] != values[i] {
+			t.Errorf("%s: decoded as %s", values[i], decoded[i])
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	value := FromUint32(300, 77)
+
+	testCases := []struct {
+		format   string
+		expected string
+	}{
+		{"%d", "300"},
+		{"%v", "300"},
+		{"%s", "300"},
+		{"%x", "12c"},
+		{"%X", "12C"},
+		{"%#x", "0x12c"},
+		{"%b", "100101100"},
+		{"%6d", "   300"},
+		{"%-6d|", "300   |"},
+		{"%08b", "100101100"},
+	}
+
+	for _, tc := range testCases {
+		if result := fmt.Sprintf(tc.format, value); result != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.format, tc.expected, result)
+		}
+	}
+
+	for _, value := range randomValues(5) {
+		for _, verb := range []string{"%d", "%x", "%b"} {
+			if fmt.Sprintf(verb, value) != fmt.Sprintf(verb, value.ToBig()) {
+				t.Errorf("%s: %s differs from big.Int", value, verb)
+			}
+		}
+	}
+}
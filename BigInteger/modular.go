@@ -0,0 +1,135 @@
+package uint239
+
+// Modular arithmetic and number theory: Exp, GCD, ModInverse and Sqrt.
+// Arguments are read as their logical values, and every result comes back
+// with a zero shift.
+
+// Exp returns base^exp modulo mod, or base^exp modulo 2^239 when mod is
+// zero.
+func Exp(base, exp, mod Uint239) Uint239 {
+	baseValue := removeShift(base)
+	expValue := removeShift(exp)
+	modValue := removeShift(mod)
+
+	multiply := multiplyRaw
+	result := FromUint32(1, 0)
+
+	if !isZeroRaw(modValue) {
+		multiply = func(lhs, rhs Uint239) Uint239 { return multiplyModRaw(lhs, rhs, modValue) }
+		_, baseValue = divModRaw(baseValue, modValue)
+		_, result = divModRaw(result, modValue)
+	}
+
+	for bit := 238; bit >= 0; bit-- {
+
+		result = multiply(result, result)
+
+		if bitRaw(expValue, bit) != 0 {
+			result = multiply(result, baseValue)
+		}
+	}
+
+	return result
+}
+
+// GCD returns the greatest common divisor; GCD(0, 0) is 0.
+func GCD(lhs, rhs Uint239) Uint239 {
+	a := removeShift(lhs)
+	b := removeShift(rhs)
+
+	for !isZeroRaw(b) {
+		_, remainder := divModRaw(a, b)
+		a, b = b, remainder
+	}
+
+	return a
+}
+
+// ModInverse returns x with value*x = 1 modulo mod. It fails with
+// ErrDivisionByZero for a zero modulus and ErrNoInverse when value and mod
+// are not coprime.
+func ModInverse(value, mod Uint239) (Uint239, error) {
+	modValue := removeShift(mod)
+	if isZeroRaw(modValue) {
+		return Uint239{}, ErrDivisionByZero
+	}
+
+	// Extended Euclid keeping the coefficients of value modulo mod, so
+	// that they never go negative.
+	_, a := divModRaw(removeShift(value), modValue)
+	b := modValue
+	x, nextX := FromUint32(1, 0), Uint239{}
+
+	for !isZeroRaw(b) {
+		quotient, remainder := divModRaw(a, b)
+		a, b = b, remainder
+
+		step := multiplyModRaw(quotient, nextX, modValue)
+		x, nextX = nextX, subtractModRaw(x, step, modValue)
+	}
+
+	if compareRaw(a, FromUint32(1, 0)) != 0 {
+		return Uint239{}, ErrNoInverse
+	}
+
+	_, x = divModRaw(x, modValue)
+
+	return x, nil
+}
+
+// Sqrt returns the integer square root, the largest x with x*x <= value.
+func Sqrt(value Uint239) Uint239 {
+	n := removeShift(value)
+	if isZeroRaw(n) {
+		return n
+	}
+
+	// Newton's method from a power of two above the root only decreases.
+	x := Lsh(FromUint32(1, 0), uint((BitLen(n)+1)/2))
+
+	for {
+		quotient := divideRaw(n, x)
+		next := Rsh(addRaw(x, quotient), 1)
+
+		if compareRaw(next, x) >= 0 {
+			return x
+		}
+
+		x = next
+	}
+}
+
+// multiplyModRaw returns lhs*rhs modulo mod for lhs and rhs below mod,
+// doubling and adding bit by bit so that nothing exceeds 2^240.
+func multiplyModRaw(lhs, rhs, mod Uint239) Uint239 {
+	result := Uint239{}
+
+	for bit := BitLen(rhs) - 1; bit >= 0; bit-- {
+
+		result = addModRaw(result, result, mod)
+
+		if bitRaw(rhs, bit) != 0 {
+			result = addModRaw(result, lhs, mod)
+		}
+	}
+
+	return result
+}
+
+func addModRaw(lhs, rhs, mod Uint239) Uint239 {
+	sum := addRaw(lhs, rhs)
+
+	if compareRaw(sum, mod) >= 0 {
+		sum = subtractRaw(sum, mod)
+	}
+
+	return sum
+}
+
+func subtractModRaw(lhs, rhs, mod Uint239) Uint239 {
+	if compareRaw(lhs, rhs) >= 0 {
+		return subtractRaw(lhs, rhs)
+	}
+
+	return subtractRaw(addRaw(lhs, mod), rhs)
+}
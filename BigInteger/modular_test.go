@@ -0,0 +1,116 @@
+package uint239
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestExpMatchesBig(t *testing.T) {
+	rng := rand.New(rand.NewSource(23))
+	wrap := new(big.Int).Lsh(big.NewInt(1), 239)
+
+	for i := 0; i < 100; i++ {
+		base := randomBig(rng, 239)
+		exp := randomBig(rng, 239)
+		mod := randomBig(rng, 239)
+
+		baseValue, _ := FromBig(base, 0)
+		expValue, _ := FromBig(exp, 0)
+		modValue, _ := FromBig(mod, 0)
+
+		expected := new(big.Int).Exp(base, exp, mod)
+		if mod.Sign() == 0 {
+			expected = new(big.Int).Exp(base, exp, wrap)
+		}
+
+		if result := Exp(baseValue, expValue, modValue); result.ToBig().Cmp(expected) != 0 {
+			t.Fatalf("%v^%v mod %v: expected %v, got %v", base, exp, mod, expected, result.ToBig())
+		}
+	}
+
+	if Exp(FromUint32(3, 0), FromUint32(5, 0), Uint239{}).ToBig().Int64() != 243 {
+		t.Errorf("Expected 3^5 = 243 without a modulus")
+	}
+
+	if Exp(FromUint32(7, 0), Uint239{}, FromUint32(1, 0)).ToBig().Sign() != 0 {
+		t.Errorf("Expected anything modulo 1 to be 0")
+	}
+}
+
+func TestGCDMatchesBig(t *testing.T) {
+	rng := rand.New(rand.NewSource(24))
+
+	for i := 0; i < 300; i++ {
+		common := randomBig(rng, 80)
+		lhs := new(big.Int).Mul(randomBig(rng, 150), common)
+		rhs := new(big.Int).Mul(randomBig(rng, 150), common)
+
+		lhsValue, _ := FromBig(lhs, 0)
+		rhsValue, _ := FromBig(rhs, 0)
+
+		expected := new(big.Int).GCD(nil, nil, lhs, rhs)
+		if result := GCD(lhsValue, rhsValue); result.ToBig().Cmp(expected) != 0 {
+			t.Fatalf("GCD %v %v: expected %v, got %v", lhs, rhs, expected, result.ToBig())
+		}
+	}
+}
+
+func TestModInverse(t *testing.T) {
+	rng := rand.New(rand.NewSource(25))
+
+	for i := 0; i < 300; i++ {
+		value := randomBig(rng, 239)
+		mod := randomBig(rng, 239)
+		if mod.Sign() == 0 {
+			continue
+		}
+
+		valueValue, _ := FromBig(value, 0)
+		modValue, _ := FromBig(mod, 0)
+
+		inverse, err := ModInverse(valueValue, modValue)
+
+		expected := new(big.Int).ModInverse(value, mod)
+		if mod.Cmp(big.NewInt(1)) == 0 {
+			expected = big.NewInt(0)
+		}
+		if expected == nil {
+			if !errors.Is(err, ErrNoInverse) {
+				t.Fatalf("%v mod %v: expected no inverse, got %v, %v", value, mod, inverse.ToBig(), err)
+			}
+			continue
+		}
+
+		if err != nil || inverse.ToBig().Cmp(expected) != 0 {
+			t.Fatalf("%v mod %v: expected %v, got %v, %v", value, mod, expected, inverse.ToBig(), err)
+		}
+	}
+
+	if _, err := ModInverse(FromUint32(3, 0), Uint239{}); !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("Expected a zero modulus to fail, got %v", err)
+	}
+}
+
+func TestSqrt(t *testing.T) {
+	rng := rand.New(rand.NewSource(26))
+
+	for i := 0; i < 300; i++ {
+		value := randomBig(rng, 239)
+
+		valueValue, _ := FromBig(value, 0)
+
+		expected := new(big.Int).Sqrt(value)
+		if result := Sqrt(valueValue); result.ToBig().Cmp(expected) != 0 {
+			t.Fatalf("Sqrt %v: expected %v, got %v", value, expected, result.ToBig())
+		}
+	}
+
+	for _, value := range []uint32{0, 1, 2, 3, 4, 15, 16, 17} {
+		root := Sqrt(FromUint32(value, 0)).ToBig().Int64()
+		if root*root > int64(value) || (root+1)*(root+1) <= int64(value) {
+			t.Errorf("Sqrt %d: got %d", value, root)
+		}
+	}
+}
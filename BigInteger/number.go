@@ -1,5 +1,26 @@
 package uint239
 
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrSyntax is returned for strings that are not a decimal number.
+	ErrSyntax = errors.New("uint239: invalid syntax")
+	// ErrOverflow is returned for values that do not fit in 239 bits.
+	ErrOverflow = errors.New("uint239: value out of range")
+	// ErrUnderflow is returned when a subtraction would go below zero.
+	ErrUnderflow = errors.New("uint239: subtraction underflow")
+	// ErrDivisionByZero is returned when the divisor is zero.
+	ErrDivisionByZero = errors.New("uint239: division by zero")
+	// ErrNoInverse is returned by ModInverse when the value and the modulus
+	// are not coprime.
+	ErrNoInverse = errors.New("uint239: no modular inverse")
+	// ErrNegative is returned by FromBig for values below zero.
+	ErrNegative = errors.New("uint239: negative value")
+)
+
 type Uint239 struct {
 	Data [35]byte
 }
@@ -34,54 +55,72 @@ func FromUint32(value uint32, shift uint32) Uint239 {
 	return result
 }
 
-func FromString(str string, shift uint32) Uint239 {
-	var value uint64 = 0
+// FromString parses a decimal number of up to 239 bits.
+func FromString(str string, shift uint32) (Uint239, error) {
+	if len(str) == 0 {
+		return Uint239{}, fmt.Errorf("%w: empty string", ErrSyntax)
+	}
+
+	value := Uint239{}
 
 	for _, ch := range str {
-		if ch >= '0' && ch <= '9' {
-			value = value*10 + uint64(ch-'0')
+		if ch < '0' || ch > '9' {
+			return Uint239{}, fmt.Errorf("%w: %q", ErrSyntax, str)
+		}
+
+		var overflow bool
+		value, overflow = multiplyAddSmallRaw(value, 10, uint32(ch-'0'))
+		if overflow {
+			return Uint239{}, fmt.Errorf("%w: %s", ErrOverflow, str)
 		}
 	}
 
-	return FromUint32(uint32(value), shift)
+	return applyShift(value, shift%239), nil
 }
 
+// Add returns lhs + rhs modulo 2^239. The shift of the result is the sum of
+// the operands' shifts modulo 239.
 func Add(lhs, rhs Uint239) Uint239 {
 
 	lhsShift := GetShift(lhs)
 	rhsShift := GetShift(rhs)
 
-	resultShift := lhsShift + rhsShift
+	resultShift := addShifts(lhsShift, rhsShift)
 
 	lhsValue := removeShift(lhs)
 	rhsValue := removeShift(rhs)
 
-	resultValue := addRaw(lhsValue, rhsValue)
+	resultValue := truncateRaw(addRaw(lhsValue, rhsValue))
 
 	return applyShift(resultValue, resultShift)
 }
 
+// Subtract returns lhs - rhs modulo 2^239, so it wraps around when rhs is
+// larger. The shift of the result is the difference of the operands'
+// shifts modulo 239.
 func Subtract(lhs, rhs Uint239) Uint239 {
 
 	lhsShift := GetShift(lhs)
 	rhsShift := GetShift(rhs)
 
-	resultShift := lhsShift - rhsShift
+	resultShift := subtractShifts(lhsShift, rhsShift)
 
 	lhsValue := removeShift(lhs)
 	rhsValue := removeShift(rhs)
 
-	resultValue := subtractRaw(lhsValue, rhsValue)
+	resultValue := truncateRaw(subtractRaw(lhsValue, rhsValue))
 
 	return applyShift(resultValue, resultShift)
 }
 
+// Multiply returns lhs * rhs modulo 2^239. The shift of the result is the
+// sum of the operands' shifts modulo 239.
 func Multiply(lhs, rhs Uint239) Uint239 {
 
 	lhsShift := GetShift(lhs)
 	rhsShift := GetShift(rhs)
 
-	resultShift := lhsShift + rhsShift
+	resultShift := addShifts(lhsShift, rhsShift)
 
 	lhsValue := removeShift(lhs)
 	rhsValue := removeShift(rhs)
@@ -91,12 +130,15 @@ func Multiply(lhs, rhs Uint239) Uint239 {
 	return applyShift(resultValue, resultShift)
 }
 
+// Divide returns the integer quotient lhs / rhs, or 0 when rhs is zero. The
+// shift of the result is the difference of the operands' shifts modulo
+// 239.
 func Divide(lhs, rhs Uint239) Uint239 {
 
 	lhsShift := GetShift(lhs)
 	rhsShift := GetShift(rhs)
 
-	resultShift := lhsShift - rhsShift
+	resultShift := subtractShifts(lhsShift, rhsShift)
 
 	lhsValue := removeShift(lhs)
 	rhsValue := removeShift(rhs)
@@ -128,13 +170,33 @@ func NotEqual(lhs, rhs Uint239) bool {
 	return !Equal(lhs, rhs)
 }
 
+// String formats the logical value in decimal.
 func (u Uint239) String() string {
+	value := removeShift(u)
+
+	if isZeroRaw(value) {
+		return "0"
+	}
 
-	return "Implement me"
+	digits := make([]byte, 0, 72)
+
+	for !isZeroRaw(value) {
+		var digit uint32
+		value, digit = divideSmallRaw(value, 10)
+		digits = append(digits, byte('0'+digit))
+	}
+
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+
+	return string(digits)
 }
 
+// GetShift returns the circular shift kept in the service bits, reduced
+// modulo 239.
 func GetShift(value Uint239) uint32 {
-	var shift uint32
+	var shift uint64
 
 	for i := 0; i < len(value.Data); i++ {
 
@@ -144,7 +206,24 @@ func GetShift(value Uint239) uint32 {
 		}
 	}
 
-	return shift
+	return uint32(shift % 239)
+}
+
+// Normalize returns the canonical form of the value: the shift is below
+// 239, and only the 239 low bits of the rotated value are set. Every
+// function of the package returns canonical values.
+func Normalize(value Uint239) Uint239 {
+	return applyShift(removeShift(value), GetShift(value))
+}
+
+// addShifts and subtractShifts combine shifts modulo 239, the period of
+// the rotation.
+func addShifts(lhs, rhs uint32) uint32 {
+	return (lhs%239 + rhs%239) % 239
+}
+
+func subtractShifts(lhs, rhs uint32) uint32 {
+	return (lhs%239 + 239 - rhs%239) % 239
 }
 
 func createServiceBits(shift uint32) [35]byte {
@@ -160,29 +239,20 @@ func createServiceBits(shift uint32) [35]byte {
 	return result
 }
 
+// circularLeftShift rotates the 239 low bits of the limbs left by shift.
+// The bits above them are dropped.
 func circularLeftShift(value []byte, shift uint32) []byte {
 	result := make([]byte, len(value))
 
-	byteShift := shift / 7
-	bitShift := shift % 7
-
-	for i := 0; i < len(value); i++ {
-
-		srcIdx := (i - int(byteShift) + len(value)) % len(value)
+	shift %= 239
 
-		srcBits := value[srcIdx] & 0x7F
+	for bit := 0; bit < 239; bit++ {
 
-		if bitShift > 0 {
+		srcBits := (value[len(value)-1-bit/7] >> (bit % 7)) & 1
 
-			prevIdx := (srcIdx - 1 + len(value)) % len(value)
-			prevBits := value[prevIdx] & 0x7F
+		target := (bit + int(shift)) % 239
 
-			result[i] = (srcBits << bitShift) | (prevBits >> (7 - bitShift))
-			result[i] &= 0x7F
-		} else {
-
-			result[i] = srcBits
-		}
+		result[len(value)-1-target/7] |= srcBits << (target % 7)
 	}
 
 	return result
@@ -211,16 +281,17 @@ func removeShift(value Uint239) Uint239 {
 		}
 	}
 
-	return result
+	return truncateRaw(result)
 }
 
 func circularRightShift(value []byte, shift uint32) []byte {
 
 	totalBits := uint32(239)
-	return circularLeftShift(value, totalBits-shift%totalBits)
+	return circularLeftShift(value, (totalBits-shift%totalBits)%totalBits)
 }
 
 func applyShift(value Uint239, shift uint32) Uint239 {
+	shift %= 239
 
 	valueBytes := make([]byte, 35)
 	for i := 0; i < 35; i++ {
@@ -278,47 +349,178 @@ func subtractRaw(lhs, rhs Uint239) Uint239 {
 	return result
 }
 
+// multiplyRaw multiplies the limbs schoolbook style, keeping the product
+// modulo 2^239.
 func multiplyRaw(lhs, rhs Uint239) Uint239 {
+	var columns [35]uint32
+	size := len(lhs.Data)
 
-	lhsVal := toUint32(lhs)
-	rhsVal := toUint32(rhs)
+	for i := 0; i < size; i++ {
 
-	product := lhsVal * rhsVal
+		lhsBits := uint32(lhs.Data[size-1-i] & 0x7F)
+		if lhsBits == 0 {
+			continue
+		}
 
-	return rawFromUint32(product)
+		for j := 0; i+j < size; j++ {
+			columns[i+j] += lhsBits * uint32(rhs.Data[size-1-j]&0x7F)
+		}
+	}
+
+	result := Uint239{}
+	var carry uint32
+
+	for i := 0; i < size; i++ {
+
+		sum := columns[i] + carry
+
+		result.Data[size-1-i] = byte(sum & 0x7F)
+
+		carry = sum >> 7
+	}
+
+	return truncateRaw(result)
 }
 
+// divideRaw returns the quotient of the long division. Division by zero
+// gives 0.
 func divideRaw(lhs, rhs Uint239) Uint239 {
+	quotient, _ := divModRaw(lhs, rhs)
 
-	lhsVal := toUint32(lhs)
-	rhsVal := toUint32(rhs)
+	return quotient
+}
+
+// divModRaw divides bit by bit, from the highest bit of lhs down, and
+// returns the quotient and the remainder.
+func divModRaw(lhs, rhs Uint239) (Uint239, Uint239) {
+	quotient := Uint239{}
+	remainder := Uint239{}
 
-	var quotient uint32
-	if rhsVal != 0 {
-		quotient = lhsVal / rhsVal
+	if isZeroRaw(rhs) {
+		return quotient, remainder
 	}
 
-	return rawFromUint32(quotient)
+	topBit := len(lhs.Data)*7 - 1
+
+	for bit := topBit; bit >= 0; bit-- {
+
+		// A bit shifted out of the top still counts, and the wrapping
+		// subtraction then gives the right remainder.
+		overflow := bitRaw(remainder, topBit) != 0
+
+		remainder = shiftLeftOneRaw(remainder)
+		remainder.Data[len(remainder.Data)-1] |= bitRaw(lhs, bit)
+
+		if overflow || compareRaw(remainder, rhs) >= 0 {
+			remainder = subtractRaw(remainder, rhs)
+			quotient.Data[len(quotient.Data)-1-bit/7] |= 1 << (bit % 7)
+		}
+	}
+
+	return quotient, remainder
 }
 
-func toUint32(value Uint239) uint32 {
-	var result uint32
+// multiplyAddSmallRaw returns value*factor + addend and reports whether the
+// result needs more than 239 bits.
+func multiplyAddSmallRaw(value Uint239, factor, addend uint32) (Uint239, bool) {
+	result := Uint239{}
+	carry := addend
+
+	for i := len(value.Data) - 1; i >= 0; i-- {
+
+		sum := uint32(value.Data[i]&0x7F)*factor + carry
+
+		result.Data[i] = byte(sum & 0x7F)
+
+		carry = sum >> 7
+	}
+
+	return truncateRaw(result), carry != 0 || result.Data[0] > 0x01
+}
+
+// divideSmallRaw divides by a divisor below 2^24 and returns the quotient
+// and the remainder.
+func divideSmallRaw(value Uint239, divisor uint32) (Uint239, uint32) {
+	result := Uint239{}
+	var remainder uint32
 
 	for i := 0; i < len(value.Data); i++ {
 
+		current := remainder<<7 | uint32(value.Data[i]&0x7F)
+
+		result.Data[i] = byte(current / divisor)
+
+		remainder = current % divisor
+	}
+
+	return result, remainder
+}
+
+// truncateRaw drops the bits above bit 238: 35 limbs of 7 bits hold 245.
+func truncateRaw(value Uint239) Uint239 {
+	value.Data[0] &= 0x01
+
+	return value
+}
+
+func isZeroRaw(value Uint239) bool {
+	for i := 0; i < len(value.Data); i++ {
+
+		if value.Data[i]&0x7F != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compareRaw returns -1, 0 or 1 as lhs is less than, equal to or greater
+// than rhs.
+func compareRaw(lhs, rhs Uint239) int {
+	for i := 0; i < len(lhs.Data); i++ {
+
+		lhsBits := lhs.Data[i] & 0x7F
+		rhsBits := rhs.Data[i] & 0x7F
+
+		if lhsBits != rhsBits {
+			if lhsBits < rhsBits {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// bitRaw returns bit number bit of the value, counting from the lowest.
+func bitRaw(value Uint239, bit int) byte {
+	return (value.Data[len(value.Data)-1-bit/7] >> (bit % 7)) & 1
+}
+
+func shiftLeftOneRaw(value Uint239) Uint239 {
+	result := Uint239{}
+	var carry byte
+
+	for i := len(value.Data) - 1; i >= 0; i-- {
+
 		bits := value.Data[i] & 0x7F
-		result = (result << 7) | uint32(bits)
+
+		result.Data[i] = (bits<<1 | carry) & 0x7F
+
+		carry = bits >> 6
 	}
 
 	return result
 }
 
-func rawFromUint32(value uint32) Uint239 {
-	result := Uint239{}
+func toUint32(value Uint239) uint32 {
+	var result uint32
 
-	for i := len(result.Data) - 1; i >= 0 && value > 0; i-- {
-		result.Data[i] = byte(value & 0x7F)
-		value >>= 7
+	for i := 0; i < len(value.Data); i++ {
+
+		bits := value.Data[i] & 0x7F
+		result = (result << 7) | uint32(bits)
 	}
 
 	return result
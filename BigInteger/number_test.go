@@ -1,6 +1,9 @@
 package uint239
 
 import (
+	"errors"
+	"math/big"
+	"math/rand"
 	"testing"
 )
 
@@ -92,7 +95,10 @@ func TestFromString(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := FromString(tc.value, tc.shift)
+			result, err := FromString(tc.value, tc.shift)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
 
 			rawValue := toUint32(removeShift(result))
 			if rawValue != tc.expected {
@@ -259,3 +265,221 @@ func TestEquality(t *testing.T) {
 		}
 	})
 }
+
+func rawFromBig(value *big.Int) Uint239 {
+	result := Uint239{}
+	digits := new(big.Int).Set(value)
+	limb := new(big.Int)
+
+	for i := len(result.Data) - 1; i >= 0; i-- {
+		digits.DivMod(digits, big.NewInt(128), limb)
+		result.Data[i] = byte(limb.Int64())
+	}
+
+	return result
+}
+
+func rawToBig(value Uint239) *big.Int {
+	result := new(big.Int)
+
+	for i := 0; i < len(value.Data); i++ {
+		result.Lsh(result, 7)
+		result.Or(result, big.NewInt(int64(value.Data[i]&0x7F)))
+	}
+
+	return result
+}
+
+func randomBig(rng *rand.Rand, bits int) *big.Int {
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(rng.Intn(bits)+1))
+	return new(big.Int).Rand(rng, limit)
+}
+
+func TestMultiplyRawMatchesBig(t *testing.T) {
+	rng := rand.New(rand.NewSource(239))
+	modulus := new(big.Int).Lsh(big.NewInt(1), 239)
+
+	for i := 0; i < 1000; i++ {
+		lhs := randomBig(rng, 239)
+		rhs := randomBig(rng, 239)
+
+		expected := new(big.Int).Mul(lhs, rhs)
+		expected.Mod(expected, modulus)
+
+		product := rawToBig(multiplyRaw(rawFromBig(lhs), rawFromBig(rhs)))
+		if product.Cmp(expected) != 0 {
+			t.Fatalf("%v * %v: expected %v, got %v", lhs, rhs, expected, product)
+		}
+	}
+}
+
+func TestDivideRawMatchesBig(t *testing.T) {
+	rng := rand.New(rand.NewSource(239))
+
+	for i := 0; i < 1000; i++ {
+		lhs := randomBig(rng, 239)
+		rhs := randomBig(rng, 239)
+		if rhs.Sign() == 0 {
+			continue
+		}
+
+		expectedQuotient, expectedRemainder := new(big.Int).QuoRem(lhs, rhs, new(big.Int))
+
+		quotient, remainder := divModRaw(rawFromBig(lhs), rawFromBig(rhs))
+		if rawToBig(quotient).Cmp(expectedQuotient) != 0 || rawToBig(remainder).Cmp(expectedRemainder) != 0 {
+			t.Fatalf("%v / %v: expected %v rem %v, got %v rem %v",
+				lhs, rhs, expectedQuotient, expectedRemainder, rawToBig(quotient), rawToBig(remainder))
+		}
+
+		if rawToBig(divideRaw(rawFromBig(lhs), rawFromBig(rhs))).Cmp(expectedQuotient) != 0 {
+			t.Fatalf("%v / %v: divideRaw disagrees with divModRaw", lhs, rhs)
+		}
+	}
+
+	// The remainder can outgrow the limbs when the divisor uses all 245 bits.
+	full := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 245), big.NewInt(1))
+	divisor := new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 244), big.NewInt(5))
+	quotient, remainder := divModRaw(rawFromBig(full), rawFromBig(divisor))
+	if rawToBig(quotient).Int64() != 1 || rawToBig(remainder).Cmp(new(big.Int).Sub(full, divisor)) != 0 {
+		t.Errorf("Expected a quotient of 1 for a divisor with the top bit set, got %v rem %v",
+			rawToBig(quotient), rawToBig(remainder))
+	}
+
+	if quotient := divideRaw(rawFromBig(big.NewInt(42)), Uint239{}); !isZeroRaw(quotient) {
+		t.Errorf("Expected division by zero to give 0")
+	}
+}
+
+func TestDecimalRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(10))
+	maximum := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 239), big.NewInt(1))
+
+	values := []*big.Int{big.NewInt(0), big.NewInt(7), big.NewInt(4294967296), maximum}
+	for i := 0; i < 200; i++ {
+		values = append(values, randomBig(rng, 239))
+	}
+
+	for _, value := range values {
+		result, err := FromString(value.String(), 0)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", value, err)
+		}
+
+		if rawToBig(result).Cmp(value) != 0 {
+			t.Errorf("%v: parsed as %v", value, rawToBig(result))
+		}
+
+		if result.String() != value.String() {
+			t.Errorf("%v: formatted as %s", value, result.String())
+		}
+	}
+}
+
+func TestFromStringErrors(t *testing.T) {
+	tooLarge := new(big.Int).Lsh(big.NewInt(1), 239).String()
+
+	testCases := []struct {
+		value    string
+		expected error
+	}{
+		{value: "", expected: ErrSyntax},
+		{value: "12a", expected: ErrSyntax},
+		{value: "-5", expected: ErrSyntax},
+		{value: " 5", expected: ErrSyntax},
+		{value: tooLarge, expected: ErrOverflow},
+		{value: tooLarge + "0", expected: ErrOverflow},
+	}
+
+	for _, tc := range testCases {
+		if _, err := FromString(tc.value, 0); !errors.Is(err, tc.expected) {
+			t.Errorf("%q: expected %v, got %v", tc.value, tc.expected, err)
+		}
+	}
+}
+
+func FuzzFromUint32RemoveShift(f *testing.F) {
+	for _, shift := range []uint32{0, 1, 7, 8, 238, 239, 240, 1 << 31, 1<<32 - 1} {
+		f.Add(uint32(42), shift)
+		f.Add(uint32(1<<32-1), shift)
+	}
+
+	f.Fuzz(func(t *testing.T, value, shift uint32) {
+		result := FromUint32(value, shift)
+
+		if raw := toUint32(removeShift(result)); raw != value {
+			t.Fatalf("FromUint32(%d, %d): removeShift gives %d", value, shift, raw)
+		}
+
+		if GetShift(result) != shift%239 {
+			t.Fatalf("FromUint32(%d, %d): shift %d", value, shift, GetShift(result))
+		}
+
+		if Normalize(result) != result {
+			t.Fatalf("FromUint32(%d, %d) is not canonical", value, shift)
+		}
+	})
+}
+
+func FuzzShiftArithmetic(f *testing.F) {
+	f.Add(uint32(30), uint32(10), uint32(200), uint32(100))
+	f.Add(uint32(7), uint32(7), uint32(3), uint32(5))
+
+	f.Fuzz(func(t *testing.T, lhs, rhs, lhsShift, rhsShift uint32) {
+		lhsValue := FromUint32(lhs, lhsShift)
+		rhsValue := FromUint32(rhs, rhsShift)
+
+		sum := Add(lhsValue, rhsValue)
+		if sum.ToBig().Uint64() != uint64(lhs)+uint64(rhs) {
+			t.Fatalf("%d + %d: got %v", lhs, rhs, sum.ToBig())
+		}
+
+		if GetShift(sum) != (lhsShift%239+rhsShift%239)%239 {
+			t.Fatalf("shift of the sum: %d + %d gave %d", lhsShift, rhsShift, GetShift(sum))
+		}
+
+		difference := Subtract(lhsValue, rhsValue)
+		if GetShift(difference) != (lhsShift%239+239-rhsShift%239)%239 {
+			t.Fatalf("shift of the difference: %d - %d gave %d", lhsShift, rhsShift, GetShift(difference))
+		}
+	})
+}
+
+func TestRemoveShiftEveryShift(t *testing.T) {
+	for shift := uint32(0); shift < 3*239; shift++ {
+		for _, value := range []uint32{1, 42, 1 << 31, 1<<32 - 1} {
+
+			if raw := toUint32(removeShift(FromUint32(value, shift))); raw != value {
+				t.Fatalf("FromUint32(%d, %d): removeShift gives %d", value, shift, raw)
+			}
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	value := FromUint32(1000, 0)
+
+	// Service bits for a shift of 300 = 239 + 61, with the value rotated
+	// by 61, and junk above bit 238.
+	rotated := circularLeftShift(value.Data[:], 61)
+	serviceBits := createServiceBits(300)
+
+	nonCanonical := Uint239{}
+	for i := range nonCanonical.Data {
+		nonCanonical.Data[i] = rotated[i] | serviceBits[i]
+	}
+	nonCanonical.Data[0] |= 0x7E
+
+	normalized := Normalize(nonCanonical)
+
+	if GetShift(normalized) != 61 {
+		t.Errorf("Expected shift 61, got %d", GetShift(normalized))
+	}
+
+	if normalized != FromUint32(1000, 61) {
+		t.Errorf("Expected the canonical form of 1000 shifted by 61")
+	}
+
+	if !Equal(normalized, nonCanonical) || Normalize(normalized) != normalized {
+		t.Errorf("Expected Normalize to keep the value and be idempotent")
+	}
+}